@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// amendmentSuffix matches AusTender amendment suffixes such as "-A2".
+var amendmentSuffix = regexp.MustCompile(`-A(\d+)$`)
+
+// canonicalContractID strips whitespace, case and any amendment suffix so a
+// contract and all its amendments share one ID.
+func canonicalContractID(id string) string {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	return amendmentSuffix.ReplaceAllString(id, "")
+}
+
+// amendmentNumber returns N for "CN123-AN" and 0 for an original notice.
+func amendmentNumber(id string) int {
+	m := amendmentSuffix.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(id)))
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+func (c *contract) isUpdate() bool {
+	return c.Amends != "" || amendmentNumber(c.CN_ID) > 0
+}
+
+// searchTotals splits a total into the originally awarded value and the
+// growth (or shrinkage) added by amendments.
+type searchTotals struct {
+	Original decimal.Decimal `json:"original"`
+	Amended  decimal.Decimal `json:"amended"`
+	Final    decimal.Decimal `json:"final"`
+}
+
+// contractAggregate tracks the earliest and latest notice seen for one
+// contract. An amendment notice carries the contract's revised value.
+type contractAggregate struct {
+	first, latest *contract
+}
+
+// aggregateContracts collapses notices into one entry per contract, keeping
+// the latest amendment's value, and returns them ordered by contract ID.
+func aggregateContracts(notices []*contract) ([]*contract, searchTotals) {
+	aggregates := map[string]*contractAggregate{}
+	for _, n := range notices {
+		id := canonicalContractID(n.CN_ID)
+		a, ok := aggregates[id]
+		if !ok {
+			aggregates[id] = &contractAggregate{first: n, latest: n}
+			continue
+		}
+		if amendmentNumber(n.CN_ID) < amendmentNumber(a.first.CN_ID) {
+			a.first = n
+		}
+		if amendmentNumber(n.CN_ID) > amendmentNumber(a.latest.CN_ID) {
+			a.latest = n
+		}
+	}
+
+	ids := make([]string, 0, len(aggregates))
+	for id := range aggregates {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	contracts := make([]*contract, 0, len(ids))
+	totals := searchTotals{}
+	for _, id := range ids {
+		a := aggregates[id]
+		contracts = append(contracts, a.latest)
+		totals.Original = totals.Original.Add(a.first.Contract_Value)
+		totals.Final = totals.Final.Add(a.latest.Contract_Value)
+	}
+	totals.Amended = totals.Final.Sub(totals.Original)
+	return contracts, totals
+}
+
+// amendmentHistories groups notices by canonical contract ID, each ordered
+// original first then by amendment number. A notice seen more than once,
+// such as one listed on two result pages, appears once.
+func amendmentHistories(notices []*contract) map[string][]*contract {
+	type noticeKey struct {
+		id        string
+		amendment int
+	}
+	seen := map[noticeKey]bool{}
+	histories := map[string][]*contract{}
+	for _, n := range notices {
+		id := canonicalContractID(n.CN_ID)
+		key := noticeKey{id, amendmentNumber(n.CN_ID)}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		histories[id] = append(histories[id], n)
+	}
+	for _, h := range histories {
+		sort.SliceStable(h, func(i, j int) bool {
+			return amendmentNumber(h[i].CN_ID) < amendmentNumber(h[j].CN_ID)
+		})
+	}
+	return histories
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalContractID(t *testing.T) {
+	assert.Equal(t, "CN3482539", canonicalContractID("CN3482539-A2"))
+	assert.Equal(t, "CN3482539", canonicalContractID(" cn3482539 "))
+	assert.Equal(t, 2, amendmentNumber("CN3482539-A2"))
+	assert.Equal(t, 0, amendmentNumber("CN3482539"))
+}
+
+func TestAggregateContractsSplitsAmendments(t *testing.T) {
+	notice := func(id, amends, value string) *contract {
+		return &contract{CN_ID: id, Amends: amends, Contract_Value: decimal.RequireFromString(value)}
+	}
+	notices := []*contract{
+		// Amended upward twice, seen out of order.
+		notice("CN1-A2", "CN1", "180"),
+		notice("CN1", "", "100"),
+		notice("CN1-A1", "CN1", "150"),
+		// Amended downward.
+		notice("CN2", "", "500"),
+		notice("CN2-A1", "CN2", "400"),
+		// Never amended.
+		notice("CN3", "", "50"),
+	}
+
+	contracts, totals := aggregateContracts(notices)
+	assert.Len(t, contracts, 3)
+	assert.Equal(t, "CN1-A2", contracts[0].CN_ID, "latest amendment wins")
+	assert.Equal(t, "CN2-A1", contracts[1].CN_ID)
+	assert.Equal(t, "650", totals.Original.String())
+	assert.Equal(t, "-20", totals.Amended.String())
+	assert.Equal(t, "630", totals.Final.String())
+}
+
+func TestAggregateContractsWithoutOriginalNotice(t *testing.T) {
+	contracts, totals := aggregateContracts([]*contract{
+		{CN_ID: "CN9-A3", Contract_Value: decimal.NewFromInt(30)},
+		{CN_ID: "CN9-A1", Contract_Value: decimal.NewFromInt(10)},
+	})
+	assert.Len(t, contracts, 1)
+	assert.Equal(t, "10", totals.Original.String(), "earliest amendment seen stands in for the original")
+	assert.Equal(t, "30", totals.Final.String())
+}
+
+func TestHumanSinkShowsAmendmentSplit(t *testing.T) {
+	var out bytes.Buffer
+	sink, _ := newOutputSink("human", &out, nil, nil)
+	sink.OnMatch(&contract{CN_ID: "CN1-A1", Amends: "CN1", Contract_Value: decimal.NewFromInt(150)})
+	sink.OnTotal(searchTotals{
+		Original: decimal.NewFromInt(100),
+		Amended:  decimal.NewFromInt(50),
+		Final:    decimal.NewFromInt(150),
+	}, "")
+	assert.Contains(t, out.String(), "$150.00 [amendment]\n")
+	assert.Contains(t, out.String(), "Originally awarded $100.00, +$50.00 through amendments\n")
+}
+
+func TestAmendmentHistories(t *testing.T) {
+	notice := func(id, value, date string) *contract {
+		return &contract{CN_ID: id, Publish_Date: date, Contract_Value: decimal.RequireFromString(value)}
+	}
+	notices := []*contract{
+		notice("CN1-A3", "210", "1-Mar-2020"),
+		notice("CN1-A1", "150", "1-Jan-2019"),
+		notice("CN2", "50", "1-Jan-2019"),
+		notice("CN1", "100", "1-Jul-2018"),
+		notice("CN1-A2", "180", "1-Jun-2019"),
+		// The same notice again, as when it is listed on two result pages
+		notice("CN1-A1", "150", "1-Jan-2019"),
+	}
+
+	history := amendmentHistories(notices)["CN1"]
+	ids := []string{}
+	for _, n := range history {
+		ids = append(ids, n.CN_ID)
+	}
+	assert.Equal(t, []string{"CN1", "CN1-A1", "CN1-A2", "CN1-A3"}, ids)
+
+	contracts, _ := aggregateContracts(notices)
+	assert.Equal(t, history[len(history)-1], contracts[0], "history ends at the aggregated value")
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeAmendmentHistory(&buf, history, false))
+	assert.Equal(t, `Notice  Published     Value  Change
+CN1     1-Jul-2018  $100.00
+CN1-A1  1-Jan-2019  $150.00  $50.00
+CN1-A2  1-Jun-2019  $180.00  $30.00
+CN1-A3  1-Mar-2020  $210.00  $30.00
+`, buf.String())
+}
+
+func TestContractCommand(t *testing.T) {
+	base := serveFixtures(t)
+	out, _, err := runRoot(t, "contract", "cn3500001", "--base-url", base, "--c", "KPMG", "--history", "--no-progress")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "CN3500001: Department of Defence")
+	assert.Contains(t, out, "$120,000.00")
+
+	_, _, err = runRoot(t, "contract", "CN999", "--base-url", base, "--c", "KPMG")
+	assert.EqualError(t, err, "no notices found for contract CN999")
+}
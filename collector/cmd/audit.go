@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+// procurementThresholds are the Commonwealth open-tender thresholds: $80k
+// for most agencies, $400k for construction and some corporate entities.
+// Like published contract values, they include GST.
+var procurementThresholds = []decimal.Decimal{
+	decimal.NewFromInt(80000),
+	decimal.NewFromInt(400000),
+}
+
+// thresholdResult compares contract counts just below and just above one
+// procurement threshold within a financial year.
+type thresholdResult struct {
+	FY         string          `json:"fy"`
+	Threshold  decimal.Decimal `json:"threshold"`
+	BelowBand  string          `json:"belowBand"`
+	BelowCount int             `json:"belowCount"`
+	AboveBand  string          `json:"aboveBand"`
+	AboveCount int             `json:"aboveCount"`
+	Flagged    bool            `json:"flagged"`
+}
+
+// thresholdAuditConfig tunes what counts as an anomalous cluster.
+type thresholdAuditConfig struct {
+	// Band width as a fraction of the threshold, e.g. 0.125 gives $10k at $80k
+	BandWidth decimal.Decimal
+	// Flag when below > Ratio * above
+	Ratio decimal.Decimal
+	// Ignore bands with fewer contracts than this below the threshold
+	MinCount int
+	// --normalise-gst mode the contract values were normalised to, so the
+	// thresholds can be put on the same basis
+	GSTMode string
+}
+
+var defaultThresholdAudit = thresholdAuditConfig{
+	BandWidth: decimal.RequireFromString("0.125"),
+	Ratio:     decimal.NewFromInt(2),
+	MinCount:  5,
+}
+
+// flagCluster reports whether the just-below band is anomalously fat.
+func (cfg thresholdAuditConfig) flagCluster(below, above int) bool {
+	if below < cfg.MinCount {
+		return false
+	}
+	return decimal.NewFromInt(int64(below)).GreaterThan(cfg.Ratio.Mul(decimal.NewFromInt(int64(above))))
+}
+
+// auditThresholds counts contracts in the bands either side of each
+// procurement threshold, per financial year of publish date.
+func auditThresholds(contracts []*contract, cfg thresholdAuditConfig) []thresholdResult {
+	results := []thresholdResult{}
+	byFY := map[string][]*contract{}
+	for _, c := range contracts {
+		byFY[fyKey(c)] = append(byFY[fyKey(c)], c)
+	}
+	for _, g := range groupContracts(contracts, fyKey) {
+		for _, threshold := range procurementThresholds {
+			threshold = normaliseGST(threshold, true, cfg.GSTMode).Round(2)
+			below, above := bandsAround(threshold, threshold.Mul(cfg.BandWidth))
+			r := thresholdResult{FY: g.Key, Threshold: threshold, BelowBand: below.label(), AboveBand: above.label()}
+			for _, c := range byFY[g.Key] {
+				switch {
+				case below.contains(c.Contract_Value):
+					r.BelowCount++
+				case above.contains(c.Contract_Value):
+					r.AboveCount++
+				}
+			}
+			r.Flagged = cfg.flagCluster(r.BelowCount, r.AboveCount)
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+func writeThresholdTable(w io.Writer, results []thresholdResult, colour bool) error {
+	t := newTextTable(
+		tableColumn{Heading: "FY"},
+		tableColumn{Heading: "Threshold", Right: true},
+		tableColumn{Heading: "Just below"},
+		tableColumn{Heading: "Count", Right: true},
+		tableColumn{Heading: "Just above"},
+		tableColumn{Heading: "Count", Right: true},
+		tableColumn{Heading: "Flag"},
+	)
+	for _, r := range results {
+		flag := tableCell{}
+		if r.Flagged {
+			flag = tableCell{Text: "CLUSTERED BELOW", Style: ansiRed}
+		}
+		t.addRow(
+			tableCell{Text: r.FY},
+			tableCell{Text: compactMoney(r.Threshold)},
+			tableCell{Text: r.BelowBand},
+			tableCell{Text: strconv.Itoa(r.BelowCount)},
+			tableCell{Text: r.AboveBand},
+			tableCell{Text: strconv.Itoa(r.AboveCount)},
+			flag,
+		)
+	}
+	return t.render(w, colour)
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Procurement pattern audits",
+}
+
+var auditThresholdsCmd = &cobra.Command{
+	Use:   "thresholds",
+	Short: "Compare contract counts just below and above the $80k and $400k thresholds",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		widthPct, _ := cmd.Flags().GetFloat64("band-width-pct")
+		ratio, _ := cmd.Flags().GetFloat64("ratio")
+		minCount, _ := cmd.Flags().GetInt("min-count")
+		if widthPct <= 0 || widthPct >= 100 {
+			return fmt.Errorf("--band-width-pct must be between 0 and 100")
+		}
+		audit := thresholdAuditConfig{
+			BandWidth: decimal.NewFromFloat(widthPct).Div(decimal.NewFromInt(100)),
+			Ratio:     decimal.NewFromFloat(ratio),
+			MinCount:  minCount,
+		}
+
+		cfg := resolveConfig(os.Environ())
+		req := requestFromFlags(cmd, cfg)
+		if agency, _ := cmd.Flags().GetString("agency"); agency != "" {
+			req.Agency = agency
+		}
+		audit.GSTMode = req.NormaliseGST
+		res, err := quietSearch(cmd, req)
+		if err != nil {
+			return err
+		}
+		results := auditThresholds(res.Contracts, audit)
+		if asJSON {
+			writeGSTNote(cmd.ErrOrStderr(), req.NormaliseGST)
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(results)
+		}
+		if err := writeThresholdTable(cmd.OutOrStdout(), results, colourEnabled(cmd.OutOrStdout(), cfg)); err != nil {
+			return err
+		}
+		writeGSTNote(cmd.OutOrStdout(), req.NormaliseGST)
+		return nil
+	},
+}
+
+func init() {
+	widthPct, _ := defaultThresholdAudit.BandWidth.Mul(decimal.NewFromInt(100)).Float64()
+	ratio, _ := defaultThresholdAudit.Ratio.Float64()
+	auditThresholdsCmd.Flags().String("agency", "", "Agency to audit (same as --d)")
+	auditThresholdsCmd.Flags().Bool("json", false, "Print results as JSON")
+	auditThresholdsCmd.Flags().Float64("band-width-pct", widthPct, "Band width either side of a threshold, as a percentage of it")
+	auditThresholdsCmd.Flags().Float64("ratio", ratio, "Flag when the band below holds more than this many times the band above")
+	auditThresholdsCmd.Flags().Int("min-count", defaultThresholdAudit.MinCount, "Minimum contracts below a threshold before flagging")
+	auditCmd.AddCommand(auditThresholdsCmd)
+	rootCmd.AddCommand(auditCmd)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// contractsAt returns n contracts of value published on date.
+func contractsAt(n int, value int64, date string) []*contract {
+	out := []*contract{}
+	for i := 0; i < n; i++ {
+		out = append(out, &contract{Publish_Date: date, Contract_Value: decimal.NewFromInt(value)})
+	}
+	return out
+}
+
+func TestValueBand(t *testing.T) {
+	below, above := bandsAround(decimal.NewFromInt(80000), decimal.NewFromInt(10000))
+	assert.Equal(t, "$70k-$80k", below.label())
+	assert.Equal(t, "$80k-$90k", above.label())
+	assert.True(t, below.contains(decimal.NewFromInt(70000)))
+	assert.True(t, below.contains(decimal.RequireFromString("79999.99")))
+	assert.False(t, below.contains(decimal.NewFromInt(80000)))
+	assert.True(t, above.contains(decimal.NewFromInt(80000)))
+
+	open := valueBand{Low: decimal.NewFromInt(1500000)}
+	assert.Equal(t, ">$1.5m", open.label())
+	assert.True(t, open.contains(decimal.NewFromInt(1e9)))
+}
+
+func TestAuditThresholdsFlagsClusterBelow(t *testing.T) {
+	contracts := contractsAt(9, 79500, "1-Aug-2022")
+	contracts = append(contracts, contractsAt(2, 82000, "1-Aug-2022")...)
+	// An even spread the next year should not be flagged
+	contracts = append(contracts, contractsAt(6, 75000, "1-Aug-2023")...)
+	contracts = append(contracts, contractsAt(5, 85000, "1-Aug-2023")...)
+
+	results := auditThresholds(contracts, defaultThresholdAudit)
+	assert.Len(t, results, 4)
+
+	r := results[0]
+	assert.Equal(t, "2022-23", r.FY)
+	assert.True(t, r.Threshold.Equal(decimal.NewFromInt(80000)))
+	assert.Equal(t, 9, r.BelowCount)
+	assert.Equal(t, 2, r.AboveCount)
+	assert.True(t, r.Flagged)
+
+	assert.Equal(t, "2022-23", results[1].FY)
+	assert.Equal(t, 0, results[1].BelowCount)
+	assert.False(t, results[1].Flagged)
+
+	assert.Equal(t, "2023-24", results[2].FY)
+	assert.Equal(t, 6, results[2].BelowCount)
+	assert.Equal(t, 5, results[2].AboveCount)
+	assert.False(t, results[2].Flagged)
+}
+
+func TestThresholdFlagNeedsMinimumCount(t *testing.T) {
+	cfg := defaultThresholdAudit
+	assert.False(t, cfg.flagCluster(4, 0))
+	assert.True(t, cfg.flagCluster(5, 0))
+	assert.True(t, cfg.flagCluster(5, 2))
+	assert.False(t, cfg.flagCluster(6, 3))
+}
+
+func TestWriteThresholdTable(t *testing.T) {
+	results := auditThresholds(append(contractsAt(5, 395000, "1-Aug-2022"), contractsAt(1, 410000, "1-Aug-2022")...), defaultThresholdAudit)
+	var buf bytes.Buffer
+	assert.NoError(t, writeThresholdTable(&buf, results, false))
+	assert.Contains(t, buf.String(), "CLUSTERED BELOW")
+	assert.Contains(t, buf.String(), "$350k-$400k")
+}
+
+func TestAuditThresholdsScaleWithGSTNormalisation(t *testing.T) {
+	// $75k inc GST is just below $80k; ex GST it is $68,181.82, which must
+	// still land just below the threshold on the same basis
+	contracts := contractsAt(5, 75000, "1-Aug-2022")
+	for _, c := range contracts {
+		c.Contract_Value = normaliseGST(c.Contract_Value, true, gstExclusive)
+	}
+	cfg := defaultThresholdAudit
+	cfg.GSTMode = gstExclusive
+	results := auditThresholds(contracts, cfg)
+	assert.Equal(t, "72727.27", results[0].Threshold.StringFixed(2))
+	assert.Equal(t, 5, results[0].BelowCount)
+	assert.True(t, results[0].Flagged)
+
+	assert.Equal(t, "$63.64k-$72.73k", results[0].BelowBand)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// valueBand is the half-open range [Low, High) of contract values. A zero
+// High means the band is unbounded above.
+type valueBand struct {
+	Low  decimal.Decimal
+	High decimal.Decimal
+}
+
+func (b valueBand) contains(v decimal.Decimal) bool {
+	if v.LessThan(b.Low) {
+		return false
+	}
+	return b.High.IsZero() || v.LessThan(b.High)
+}
+
+// label renders the band compactly, e.g. "$70k-$80k" or ">$10m".
+func (b valueBand) label() string {
+	if b.High.IsZero() {
+		return ">" + compactMoney(b.Low)
+	}
+	if b.Low.IsZero() {
+		return "<" + compactMoney(b.High)
+	}
+	return compactMoney(b.Low) + "-" + compactMoney(b.High)
+}
+
+// compactMoney abbreviates amounts for labels, e.g. $80k and $1.5m, to at
+// most two decimals of the unit, so GST-adjusted edges read $72.73k.
+func compactMoney(d decimal.Decimal) string {
+	million := decimal.NewFromInt(1000000)
+	thousand := decimal.NewFromInt(1000)
+	switch {
+	case d.GreaterThanOrEqual(million):
+		return "$" + d.Div(million).Round(2).String() + "m"
+	case d.GreaterThanOrEqual(thousand):
+		return "$" + d.Div(thousand).Round(2).String() + "k"
+	}
+	return "$" + d.Round(2).String()
+}
+
+// bandsAround returns the bands of width just below and just above
+// threshold, e.g. $70k-$80k and $80k-$90k around $80k.
+func bandsAround(threshold, width decimal.Decimal) (below, above valueBand) {
+	return valueBand{Low: threshold.Sub(width), High: threshold},
+		valueBand{Low: threshold, High: threshold.Add(width)}
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+// breakdownGroup is the total and count of contracts sharing a key.
+type breakdownGroup struct {
+	Key   string
+	Total decimal.Decimal
+	Count int
+	// Set by groupContractsWithStats only
+	Stats *groupStats
+}
+
+// groupStats describes the spread of contract values within a group, so a
+// single mega-contract dominating a total is visible. Median and P90 are
+// sketch estimates within sketchAccuracy; Mean and Max are exact.
+type groupStats struct {
+	Mean   decimal.Decimal
+	Median decimal.Decimal
+	P90    decimal.Decimal
+	Max    decimal.Decimal
+}
+
+// unknownKey groups contracts whose key field is missing or unparseable.
+const unknownKey = "unknown"
+
+// groupContracts buckets contracts by key, returning groups sorted by key.
+func groupContracts(contracts []*contract, key func(*contract) string) []breakdownGroup {
+	index := map[string]int{}
+	groups := []breakdownGroup{}
+	for _, c := range contracts {
+		k := key(c)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, breakdownGroup{Key: k})
+		}
+		groups[i].Total = groups[i].Total.Add(c.Contract_Value)
+		groups[i].Count++
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Key < groups[j].Key })
+	return groups
+}
+
+// groupContractsWithStats is groupContracts plus per-group value statistics.
+func groupContractsWithStats(contracts []*contract, key func(*contract) string) []breakdownGroup {
+	sketches := map[string]*valueSketch{}
+	maxima := map[string]decimal.Decimal{}
+	for _, c := range contracts {
+		k := key(c)
+		if sketches[k] == nil {
+			sketches[k] = newValueSketch()
+			maxima[k] = c.Contract_Value
+		}
+		sketches[k].add(c.Contract_Value.InexactFloat64())
+		if c.Contract_Value.GreaterThan(maxima[k]) {
+			maxima[k] = c.Contract_Value
+		}
+	}
+	groups := groupContracts(contracts, key)
+	for i, g := range groups {
+		sketch, maximum := sketches[g.Key], maxima[g.Key]
+		// Estimates are bucket midpoints, which can overshoot the largest value
+		estimate := func(q float64) decimal.Decimal {
+			return decimal.Min(decimal.NewFromFloat(sketch.quantile(q)).Round(2), maximum)
+		}
+		groups[i].Stats = &groupStats{
+			Mean:   g.Total.Div(decimal.NewFromInt(int64(g.Count))).Round(2),
+			Median: estimate(0.5),
+			P90:    estimate(0.9),
+			Max:    maximum,
+		}
+	}
+	return groups
+}
+
+// sortByTotalDesc orders groups largest total first, breaking ties by key.
+func sortByTotalDesc(groups []breakdownGroup) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		if c := groups[i].Total.Cmp(groups[j].Total); c != 0 {
+			return c > 0
+		}
+		return groups[i].Key < groups[j].Key
+	})
+}
+
+func fyKey(c *contract) string {
+	return dateKey(c, financialYearLabel)
+}
+
+func monthKey(c *contract) string {
+	return dateKey(c, monthLabel)
+}
+
+// dateKey labels c's publish date, falling back to unknownKey.
+func dateKey(c *contract, label func(time.Time) (string, error)) string {
+	t, err := parsePublishDate(c.Publish_Date)
+	if err != nil {
+		return unknownKey
+	}
+	k, err := label(t)
+	if err != nil {
+		return unknownKey
+	}
+	return k
+}
+
+func agencyKey(c *contract) string {
+	if c.Agency == "" {
+		return unknownKey
+	}
+	return c.Agency
+}
+
+func supplierKey(c *contract) string {
+	if c.Supplier_Name == "" {
+		return unknownKey
+	}
+	return c.Supplier_Name
+}
+
+// breakdownKeys are the --by values accepted by the breakdown command.
+var breakdownKeys = map[string]func(*contract) string{
+	"fy":        fyKey,
+	"month":     monthKey,
+	"agency":    agencyKey,
+	"portfolio": portfolioKey,
+	"supplier":  supplierKey,
+}
+
+var breakdownHeadings = map[string]string{
+	"fy":        "FY",
+	"month":     "Month",
+	"agency":    "Agency",
+	"portfolio": "Portfolio",
+	"supplier":  "Supplier",
+}
+
+// writeBreakdown prints one row per group, with value statistics columns
+// when the groups carry them.
+func writeBreakdown(w io.Writer, by string, groups []breakdownGroup, colour bool) error {
+	stats := len(groups) > 0 && groups[0].Stats != nil
+	columns := []tableColumn{{Heading: breakdownHeadings[by]}, {Heading: "Total", Right: true}, {Heading: "Count", Right: true}}
+	if stats {
+		for _, h := range []string{"Mean", "Median", "P90", "Max"} {
+			columns = append(columns, tableColumn{Heading: h, Right: true})
+		}
+	}
+	t := newTextTable(columns...)
+	for _, g := range groups {
+		cells := []string{g.Key, formatMoney(g.Total), fmt.Sprint(g.Count)}
+		if g.Stats != nil {
+			cells = append(cells, formatMoney(g.Stats.Mean), formatMoney(g.Stats.Median), formatMoney(g.Stats.P90), formatMoney(g.Stats.Max))
+		}
+		t.addRow(plainCells(cells...)...)
+	}
+	return t.render(w, colour)
+}
+
+var breakdownCmd = &cobra.Command{
+	Use:   "breakdown",
+	Short: "Group matching contracts by financial year, month, agency, portfolio or supplier",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		by, _ := cmd.Flags().GetString("by")
+		withStats, _ := cmd.Flags().GetBool("stats")
+		key, ok := breakdownKeys[by]
+		if !ok {
+			return fmt.Errorf("unsupported --by %q (want fy, month, agency, portfolio or supplier)", by)
+		}
+		cfg := resolveConfig(os.Environ())
+		req := requestFromFlags(cmd, cfg)
+		res, err := quietSearch(cmd, req)
+		if err != nil {
+			return err
+		}
+		groups := groupContracts(res.Contracts, key)
+		if withStats {
+			groups = groupContractsWithStats(res.Contracts, key)
+		}
+		if by == "portfolio" {
+			// Report the gap so the portfolio map can be improved
+			if msg := unmappedShare(res.Contracts); msg != "" {
+				fmt.Fprintln(cmd.ErrOrStderr(), "note: "+msg)
+			}
+		}
+		if err := writeBreakdown(cmd.OutOrStdout(), by, groups, colourEnabled(cmd.OutOrStdout(), cfg)); err != nil {
+			return err
+		}
+		writeGSTNote(cmd.OutOrStdout(), req.NormaliseGST)
+		return nil
+	},
+}
+
+func init() {
+	breakdownCmd.Flags().String("by", "fy", "Group by fy, month, agency, portfolio or supplier")
+	breakdownCmd.Flags().Bool("stats", false, "Add mean, median, p90 and max contract value per group")
+	rootCmd.AddCommand(breakdownCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupContracts(t *testing.T) {
+	contracts := []*contract{
+		{Supplier_Name: "B", Publish_Date: "1-Jul-2023", Contract_Value: decimal.NewFromInt(10)},
+		{Supplier_Name: "A", Publish_Date: "garbage", Contract_Value: decimal.NewFromInt(10)},
+		{Supplier_Name: "B", Publish_Date: "30-Jun-2023", Contract_Value: decimal.NewFromInt(5)},
+	}
+
+	bySupplier := groupContracts(contracts, supplierKey)
+	assert.Equal(t, []string{"A", "B"}, []string{bySupplier[0].Key, bySupplier[1].Key})
+	assert.Equal(t, 2, bySupplier[1].Count)
+	assert.True(t, bySupplier[1].Total.Equal(decimal.NewFromInt(15)))
+
+	sortByTotalDesc(bySupplier)
+	assert.Equal(t, "B", bySupplier[0].Key)
+
+	byFY := groupContracts(contracts, fyKey)
+	assert.Equal(t, []string{"2022-23", "2023-24", unknownKey}, []string{byFY[0].Key, byFY[1].Key, byFY[2].Key})
+}
+
+func TestSortByTotalDescBreaksTiesByKey(t *testing.T) {
+	groups := []breakdownGroup{
+		{Key: "b", Total: decimal.NewFromInt(1)},
+		{Key: "a", Total: decimal.NewFromInt(1)},
+	}
+	sortByTotalDesc(groups)
+	assert.Equal(t, "a", groups[0].Key)
+}
+
+func TestGroupContractsWithStats(t *testing.T) {
+	contracts := []*contract{}
+	for _, v := range []int64{100, 200, 300, 400, 10000} {
+		contracts = append(contracts, &contract{Agency: "A", Contract_Value: decimal.NewFromInt(v)})
+	}
+	contracts = append(contracts, &contract{Agency: "B", Contract_Value: decimal.NewFromInt(50)})
+
+	groups := groupContractsWithStats(contracts, agencyKey)
+	assert.Len(t, groups, 2)
+	a := groups[0].Stats
+	assert.True(t, a.Mean.Equal(decimal.NewFromInt(2200)))
+	assert.True(t, a.Max.Equal(decimal.NewFromInt(10000)))
+	assert.InEpsilon(t, 300, a.Median.InexactFloat64(), sketchAccuracy)
+	assert.InEpsilon(t, 10000, a.P90.InexactFloat64(), sketchAccuracy)
+	assert.True(t, groups[1].Stats.Max.Equal(decimal.NewFromInt(50)))
+}
+
+func TestBreakdownCommandStats(t *testing.T) {
+	base := serveFixtures(t)
+	out, _, err := runRoot(t, "breakdown", "--base-url", base, "--c", "KPMG", "--by", "agency", "--stats", "--no-progress")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "Agency")
+	assert.Contains(t, out, "Median")
+	assert.Contains(t, out, "$542,560.00")
+
+	_, _, err = runRoot(t, "breakdown", "--base-url", base, "--by", "category")
+	assert.Error(t, err)
+}
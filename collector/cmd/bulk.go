@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBulkIndex = "austender"
+	// bulkSource prefixes document IDs so other sources can share an index
+	bulkSource = "federal"
+	// bulkMaxAttempts bounds sends of one batch while the cluster pushes
+	// back, with a whole-request 429 or rejected items
+	bulkMaxAttempts = 5
+
+	openSearchUserEnv     = "AUSTENDER_OPENSEARCH_USER"
+	openSearchPasswordEnv = "AUSTENDER_OPENSEARCH_PASSWORD"
+	openSearchAPIKeyEnv   = "AUSTENDER_OPENSEARCH_API_KEY"
+)
+
+// bulkRetryBackoff is the pause after the first push-back; it doubles per
+// attempt.
+var bulkRetryBackoff = time.Second
+
+// bulkConfig configures the bulkfile and opensearch outputs.
+type bulkConfig struct {
+	Index     string
+	Endpoint  string
+	BatchSize int
+	// Basic auth, or an API key which takes precedence
+	User, Password, APIKey string
+	// Timeout per _bulk request; 0 waits indefinitely
+	Timeout time.Duration
+}
+
+// bulkDocument is the indexed form of a notice. Every notice of a contract
+// shares one _id, so amendments update the original document in place.
+type bulkDocument struct {
+	*contract
+	Source     string `json:"source"`
+	ContractID string `json:"contractId"`
+}
+
+type bulkIndexAction struct {
+	Index struct {
+		Index string `json:"_index"`
+		ID    string `json:"_id"`
+		// External versioning by amendment number keeps the latest
+		// amendment even when notices arrive out of order
+		Version     int    `json:"version"`
+		VersionType string `json:"version_type"`
+	} `json:"index"`
+}
+
+// appendBulkDocument appends the action and source lines for c to buf.
+func appendBulkDocument(buf *bytes.Buffer, index string, c *contract) error {
+	id := canonicalContractID(c.CN_ID)
+	var action bulkIndexAction
+	action.Index.Index = index
+	action.Index.ID = bulkSource + ":" + id
+	action.Index.Version = amendmentNumber(c.CN_ID)
+	action.Index.VersionType = "external_gte"
+	enc := json.NewEncoder(buf)
+	if err := enc.Encode(action); err != nil {
+		return err
+	}
+	return enc.Encode(bulkDocument{contract: c, Source: bulkSource, ContractID: id})
+}
+
+// bulkSink writes matches as OpenSearch/Elasticsearch _bulk documents,
+// handing each full batch to send. It keeps the first error for Err since
+// sink methods cannot fail.
+type bulkSink struct {
+	index     string
+	batchSize int
+	send      func(batch []byte) error
+	// Receives a one-line summary at the end of the run, if set
+	report   io.Writer
+	errW     io.Writer
+	progress *progressPrinter
+	buf      bytes.Buffer
+	pending  int
+	indexed  int
+	err      error
+}
+
+// newBulkSink returns the sink for --output bulkfile, which streams bulk
+// NDJSON to w, or --output opensearch, which posts batches to cfg.Endpoint.
+func newBulkSink(format string, w, errW io.Writer, progress *progressPrinter, cfg bulkConfig) (*bulkSink, error) {
+	if cfg.Index == "" {
+		cfg.Index = defaultBulkIndex
+	}
+	s := &bulkSink{index: cfg.Index, errW: errW, progress: progress}
+	switch format {
+	case "bulkfile":
+		s.batchSize = 1
+		s.send = func(batch []byte) error {
+			_, err := w.Write(batch)
+			return err
+		}
+	case "opensearch":
+		if cfg.Endpoint == "" {
+			return nil, fmt.Errorf("--output opensearch requires --endpoint")
+		}
+		if cfg.BatchSize < 1 {
+			return nil, fmt.Errorf("--bulk-batch must be at least 1")
+		}
+		if cfg.Timeout < 0 {
+			return nil, fmt.Errorf("--bulk-timeout must not be negative")
+		}
+		s.batchSize = cfg.BatchSize
+		s.send = openSearchSender(&http.Client{Timeout: cfg.Timeout}, cfg)
+		s.report = w
+	default:
+		return nil, fmt.Errorf("unsupported bulk output %q", format)
+	}
+	return s, nil
+}
+
+func (s *bulkSink) OnProgress(done, total int) {
+	s.progress.update(done, total)
+}
+
+func (s *bulkSink) OnMatch(c *contract) {
+	if s.err != nil {
+		return
+	}
+	if s.err = appendBulkDocument(&s.buf, s.index, c); s.err != nil {
+		return
+	}
+	s.pending++
+	if s.pending >= s.batchSize {
+		s.flush()
+	}
+}
+
+func (s *bulkSink) OnWarning(msg string) {
+	fmt.Fprintln(s.errW, "warning: "+msg)
+}
+
+func (s *bulkSink) OnTotal(totals searchTotals, note string) {
+	s.progress.finish()
+	if s.err == nil {
+		s.flush()
+	}
+	if s.report != nil && s.err == nil {
+		fmt.Fprintf(s.report, "Indexed %d notices into %s\n", s.indexed, s.index)
+	}
+}
+
+// Err returns the first write or indexing error, if any.
+func (s *bulkSink) Err() error {
+	return s.err
+}
+
+func (s *bulkSink) flush() {
+	if s.pending == 0 {
+		return
+	}
+	if s.err = s.send(s.buf.Bytes()); s.err == nil {
+		s.indexed += s.pending
+	}
+	s.buf.Reset()
+	s.pending = 0
+}
+
+// bulkResponse is the part of a _bulk response needed to find failures.
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// openSearchSender posts batches to the _bulk API, retrying while the
+// cluster answers 429 Too Many Requests. Items rejected within a successful
+// response are resent on their own with the same backoff.
+func openSearchSender(client *http.Client, cfg bulkConfig) func([]byte) error {
+	url := strings.TrimRight(cfg.Endpoint, "/") + "/_bulk"
+	return func(batch []byte) error {
+		backoff := bulkRetryBackoff
+		for attempt := 1; ; attempt++ {
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(batch))
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Content-Type", "application/x-ndjson")
+			switch {
+			case cfg.APIKey != "":
+				req.Header.Set("Authorization", "ApiKey "+cfg.APIKey)
+			case cfg.User != "":
+				req.SetBasicAuth(cfg.User, cfg.Password)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return err
+			}
+			if resp.StatusCode == http.StatusTooManyRequests && attempt < bulkMaxAttempts {
+				time.Sleep(backoff)
+				backoff *= 2
+				continue
+			}
+			if resp.StatusCode/100 != 2 {
+				return fmt.Errorf("bulk index to %s: %s", url, resp.Status)
+			}
+			rejected, err := bulkItemErrors(body)
+			if err != nil || len(rejected) == 0 {
+				return err
+			}
+			if attempt >= bulkMaxAttempts {
+				return fmt.Errorf("bulk index: %d document(s) still rejected after %d attempts", len(rejected), attempt)
+			}
+			batch = bulkItemSubset(batch, rejected)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+}
+
+// bulkItemErrors reports failed items in a _bulk response. Version
+// conflicts are expected: they mean a later amendment is already indexed.
+// Items the cluster rejected under load (429 or a rejected execution) are
+// returned by position for resending rather than reported as failures.
+func bulkItemErrors(body []byte) ([]int, error) {
+	var resp bulkResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("bulk index: unreadable response: %w", err)
+	}
+	if !resp.Errors {
+		return nil, nil
+	}
+	rejected := []int{}
+	failed, first := 0, ""
+	for i, item := range resp.Items {
+		for _, result := range item {
+			if result.Error == nil || result.Status == http.StatusConflict {
+				continue
+			}
+			if result.Status == http.StatusTooManyRequests || strings.HasSuffix(result.Error.Type, "rejected_execution_exception") {
+				rejected = append(rejected, i)
+				continue
+			}
+			if failed == 0 {
+				first = result.Error.Type + ": " + result.Error.Reason
+			}
+			failed++
+		}
+	}
+	if failed == 0 {
+		return rejected, nil
+	}
+	return nil, fmt.Errorf("bulk index: %d document(s) failed, first: %s", failed, first)
+}
+
+// bulkItemSubset returns the items of batch at the given positions. Each
+// item is an action line followed by its document line.
+func bulkItemSubset(batch []byte, items []int) []byte {
+	lines := bytes.SplitAfter(batch, []byte("\n"))
+	var subset bytes.Buffer
+	for _, i := range items {
+		if 2*i+1 < len(lines) {
+			subset.Write(lines[2*i])
+			subset.Write(lines[2*i+1])
+		}
+	}
+	return subset.Bytes()
+}
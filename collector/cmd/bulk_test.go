@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bulkLines decodes NDJSON bulk lines into generic maps.
+func bulkLines(t *testing.T, body string) []map[string]any {
+	t.Helper()
+	lines := []map[string]any{}
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(sc.Bytes(), &m))
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestBulkFileSink(t *testing.T) {
+	var out bytes.Buffer
+	sink, err := newBulkSink("bulkfile", &out, nil, nil, bulkConfig{Index: "tenders"})
+	assert.NoError(t, err)
+	amended := *sampleContract
+	amended.CN_ID = "CN3482539-A2"
+	sink.OnMatch(sampleContract)
+	sink.OnMatch(&amended)
+	sink.OnTotal(searchTotals{}, "")
+	assert.NoError(t, sink.Err())
+
+	lines := bulkLines(t, out.String())
+	assert.Len(t, lines, 4)
+	action := lines[2]["index"].(map[string]any)
+	assert.Equal(t, "tenders", action["_index"])
+	assert.Equal(t, "federal:CN3482539", action["_id"], "amendments share the contract's _id")
+	assert.Equal(t, float64(2), action["version"])
+	assert.Equal(t, "external_gte", action["version_type"])
+	assert.Equal(t, "CN3482539-A2", lines[3]["cnId"])
+	assert.Equal(t, "CN3482539", lines[3]["contractId"])
+	assert.Equal(t, "federal", lines[3]["source"])
+}
+
+func TestOpenSearchSinkBatchesAndRetries(t *testing.T) {
+	prevBackoff := bulkRetryBackoff
+	bulkRetryBackoff = time.Millisecond
+	t.Cleanup(func() { bulkRetryBackoff = prevBackoff })
+
+	var mu sync.Mutex
+	bodies := []string{}
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		assert.Equal(t, "/_bulk", r.URL.Path)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, "ApiKey secret", r.Header.Get("Authorization"))
+		if calls == 1 {
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var report bytes.Buffer
+	sink, err := newBulkSink("opensearch", &report, nil, nil, bulkConfig{Endpoint: srv.URL, BatchSize: 2, APIKey: "secret"})
+	assert.NoError(t, err)
+	for _, id := range []string{"CN1", "CN2", "CN3"} {
+		c := *sampleContract
+		c.CN_ID = id
+		sink.OnMatch(&c)
+	}
+	sink.OnTotal(searchTotals{}, "")
+
+	assert.NoError(t, sink.Err())
+	assert.Equal(t, 3, calls, "one 429, then two batches")
+	assert.Len(t, bodies, 2)
+	assert.Len(t, bulkLines(t, bodies[0]), 4)
+	assert.Len(t, bulkLines(t, bodies[1]), 2)
+	assert.Equal(t, "Indexed 3 notices into austender\n", report.String())
+}
+
+func TestOpenSearchSinkResendsRejectedItems(t *testing.T) {
+	prevBackoff := bulkRetryBackoff
+	bulkRetryBackoff = time.Millisecond
+	t.Cleanup(func() { bulkRetryBackoff = prevBackoff })
+
+	var mu sync.Mutex
+	bodies := []string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		switch len(bodies) {
+		case 1:
+			w.Write([]byte(`{"errors":true,"items":[
+				{"index":{"status":201}},
+				{"index":{"status":429,"error":{"type":"es_rejected_execution_exception","reason":"queue full"}}},
+				{"index":{"status":409,"error":{"type":"version_conflict_engine_exception","reason":"newer amendment indexed"}}}]}`))
+		default:
+			w.Write([]byte(`{"errors":false,"items":[{"index":{"status":201}}]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	sink, err := newBulkSink("opensearch", io.Discard, nil, nil, bulkConfig{Endpoint: srv.URL, BatchSize: 3})
+	assert.NoError(t, err)
+	for _, id := range []string{"CN1", "CN2", "CN3"} {
+		c := *sampleContract
+		c.CN_ID = id
+		sink.OnMatch(&c)
+	}
+	sink.OnTotal(searchTotals{}, "")
+
+	assert.NoError(t, sink.Err())
+	assert.Len(t, bodies, 2)
+	resent := bulkLines(t, bodies[1])
+	assert.Len(t, resent, 2, "only the rejected item is resent")
+	assert.Equal(t, "CN2", resent[1]["cnId"])
+}
+
+func TestOpenSearchSinkGivesUpOnRejectedItems(t *testing.T) {
+	prevBackoff := bulkRetryBackoff
+	bulkRetryBackoff = time.Millisecond
+	t.Cleanup(func() { bulkRetryBackoff = prevBackoff })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors":true,"items":[{"index":{"status":429,"error":{"type":"rejected_execution_exception","reason":"queue full"}}}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	sink, err := newBulkSink("opensearch", io.Discard, nil, nil, bulkConfig{Endpoint: srv.URL, BatchSize: 1})
+	assert.NoError(t, err)
+	sink.OnMatch(sampleContract)
+	sink.OnTotal(searchTotals{}, "")
+	assert.EqualError(t, sink.Err(), "bulk index: 1 document(s) still rejected after 5 attempts")
+}
+
+func TestOpenSearchSinkBasicAuthAndItemErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "elastic", user)
+		assert.Equal(t, "changeme", pass)
+		w.Write([]byte(`{"errors":true,"items":[
+			{"index":{"status":409,"error":{"type":"version_conflict_engine_exception","reason":"newer amendment indexed"}}},
+			{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"bad field"}}}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	sink, err := newBulkSink("opensearch", io.Discard, nil, nil, bulkConfig{Endpoint: srv.URL, BatchSize: 10, User: "elastic", Password: "changeme"})
+	assert.NoError(t, err)
+	sink.OnMatch(sampleContract)
+	sink.OnTotal(searchTotals{}, "")
+	assert.EqualError(t, sink.Err(), "bulk index: 1 document(s) failed, first: mapper_parsing_exception: bad field")
+}
+
+func TestOpenSearchSinkTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	sink, err := newBulkSink("opensearch", io.Discard, nil, nil, bulkConfig{Endpoint: srv.URL, BatchSize: 1, Timeout: 50 * time.Millisecond})
+	assert.NoError(t, err)
+	sink.OnMatch(sampleContract)
+	sink.OnTotal(searchTotals{}, "")
+	assert.ErrorContains(t, sink.Err(), "Client.Timeout exceeded")
+
+	_, err = newBulkSink("opensearch", io.Discard, nil, nil, bulkConfig{Endpoint: srv.URL, BatchSize: 1, Timeout: -time.Second})
+	assert.EqualError(t, err, "--bulk-timeout must not be negative")
+}
+
+func TestNewBulkSinkRequiresEndpoint(t *testing.T) {
+	_, err := newBulkSink("opensearch", io.Discard, nil, nil, bulkConfig{BatchSize: 1})
+	assert.Error(t, err)
+}
+
+func TestRootBulkFileOutput(t *testing.T) {
+	base := serveFixtures(t)
+	out, _, err := runRoot(t, "--base-url", base, "--c", "KPMG", "--no-progress", "--output", "bulkfile")
+	assert.NoError(t, err)
+	assert.Len(t, bulkLines(t, out), 6)
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+// committedAssumptions is printed with every committed-spend table so the
+// pro-rating is never mistaken for actual payments.
+const committedAssumptions = `Assumptions: each contract counts once, at its latest amended value and
+contract period, spread evenly per day over that period (inclusive); only
+the portion on or after the as-of date is counted, split across financial
+years by days. Early terminations are not modelled.`
+
+// fyAllocation is the part of a contract's value falling in one FY.
+type fyAllocation struct {
+	FY     string
+	Amount decimal.Decimal
+}
+
+// parseContractPeriod parses periods like "22-Jan-2018 to 31-Oct-2023".
+func parseContractPeriod(s string) (time.Time, time.Time, error) {
+	parts := strings.Split(s, " to ")
+	if len(parts) != 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("unrecognised contract period %q", s)
+	}
+	start, err := parsePublishDate(parts[0])
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parsePublishDate(parts[1])
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("contract period %q ends before it starts", s)
+	}
+	return start, end, nil
+}
+
+// daysInclusive counts calendar days from start to end, both included.
+func daysInclusive(start, end time.Time) int64 {
+	return int64(end.Sub(start).Hours()/24) + 1
+}
+
+// fyStart returns 1 July of the financial year containing t.
+func fyStart(t time.Time) time.Time {
+	year := t.Year()
+	if t.Month() < time.July {
+		year--
+	}
+	return time.Date(year, time.July, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// allocateCommitted spreads value straight-line per day over [start, end]
+// and returns the portion on or after asOf, split by financial year.
+func allocateCommitted(value decimal.Decimal, start, end, asOf time.Time) []fyAllocation {
+	if end.Before(asOf) {
+		return nil
+	}
+	perDay := value.Div(decimal.NewFromInt(daysInclusive(start, end)))
+	from := start
+	if asOf.After(from) {
+		from = asOf
+	}
+	allocs := []fyAllocation{}
+	for from.Before(end) || from.Equal(end) {
+		to := fyStart(from).AddDate(1, 0, -1)
+		if to.After(end) {
+			to = end
+		}
+		days := decimal.NewFromInt(daysInclusive(from, to))
+		// from comes from a parsed period, so it is never the zero date
+		fy, _ := financialYearLabel(from)
+		allocs = append(allocs, fyAllocation{FY: fy, Amount: perDay.Mul(days)})
+		from = to.AddDate(0, 0, 1)
+	}
+	return allocs
+}
+
+// committedRow is one agency or supplier's future spend per FY.
+type committedRow struct {
+	Key   string
+	ByFY  map[string]decimal.Decimal
+	Total decimal.Decimal
+}
+
+// committedSpend aggregates future allocations of contracts by key. Contracts
+// with unparseable periods are counted in skipped.
+func committedSpend(contracts []*contract, asOf time.Time, key func(*contract) string) (rows []committedRow, fys []string, skipped int) {
+	index := map[string]int{}
+	seenFY := map[string]bool{}
+	for _, c := range contracts {
+		start, end, err := parseContractPeriod(c.Contract_Period)
+		if err != nil {
+			skipped++
+			continue
+		}
+		allocs := allocateCommitted(c.Contract_Value, start, end, asOf)
+		if len(allocs) == 0 {
+			continue
+		}
+		k := key(c)
+		i, ok := index[k]
+		if !ok {
+			i = len(rows)
+			index[k] = i
+			rows = append(rows, committedRow{Key: k, ByFY: map[string]decimal.Decimal{}})
+		}
+		for _, a := range allocs {
+			rows[i].ByFY[a.FY] = rows[i].ByFY[a.FY].Add(a.Amount)
+			rows[i].Total = rows[i].Total.Add(a.Amount)
+			seenFY[a.FY] = true
+		}
+	}
+	for fy := range seenFY {
+		fys = append(fys, fy)
+	}
+	sort.Strings(fys)
+	sort.SliceStable(rows, func(i, j int) bool {
+		if c := rows[i].Total.Cmp(rows[j].Total); c != 0 {
+			return c > 0
+		}
+		return rows[i].Key < rows[j].Key
+	})
+	return rows, fys, skipped
+}
+
+func writeCommitted(w io.Writer, asOf time.Time, rows []committedRow, fys []string, skipped int, colour bool) error {
+	fmt.Fprintf(w, "Committed spend as of %s\n\n", asOf.Format(time.DateOnly))
+	columns := []tableColumn{{}}
+	for _, fy := range fys {
+		columns = append(columns, tableColumn{Heading: fy, Right: true})
+	}
+	t := newTextTable(append(columns, tableColumn{Heading: "Total", Right: true})...)
+	for _, r := range rows {
+		cells := []string{r.Key}
+		for _, fy := range fys {
+			cells = append(cells, formatMoney(r.ByFY[fy]))
+		}
+		t.addRow(plainCells(append(cells, formatMoney(r.Total))...)...)
+	}
+	if err := t.render(w, colour); err != nil {
+		return err
+	}
+	if skipped > 0 {
+		fmt.Fprintf(w, "\n%d contract(s) skipped: contract period could not be parsed\n", skipped)
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n", committedAssumptions)
+	return err
+}
+
+var committedCmd = &cobra.Command{
+	Use:   "committed",
+	Short: "Pro-rate active contracts' remaining value across future financial years",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asOfStr, _ := cmd.Flags().GetString("as-of")
+		by, _ := cmd.Flags().GetString("by")
+		asOf := time.Now().UTC().Truncate(24 * time.Hour)
+		if asOfStr != "" {
+			var err error
+			if asOf, err = time.Parse(time.DateOnly, asOfStr); err != nil {
+				return fmt.Errorf("invalid --as-of %q (want YYYY-MM-DD)", asOfStr)
+			}
+		}
+		key := supplierKey
+		switch by {
+		case "agency":
+			key = agencyKey
+		case "supplier":
+		default:
+			return fmt.Errorf("unsupported --by %q (want agency or supplier)", by)
+		}
+
+		cfg := resolveConfig(os.Environ())
+		req := requestFromFlags(cmd, cfg)
+		res, err := quietSearch(cmd, req)
+		if err != nil {
+			return err
+		}
+		rows, fys, skipped := committedSpend(res.Contracts, asOf, key)
+		if err := writeCommitted(cmd.OutOrStdout(), asOf, rows, fys, skipped, colourEnabled(cmd.OutOrStdout(), cfg)); err != nil {
+			return err
+		}
+		writeGSTNote(cmd.OutOrStdout(), req.NormaliseGST)
+		return nil
+	},
+}
+
+func init() {
+	committedCmd.Flags().String("as-of", "", "Date to measure remaining commitments from (YYYY-MM-DD, default today)")
+	committedCmd.Flags().String("by", "agency", "Group rows by agency or supplier")
+	rootCmd.AddCommand(committedCmd)
+}
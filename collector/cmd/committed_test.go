@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func assertAllocs(t *testing.T, want map[string]string, got []fyAllocation) {
+	t.Helper()
+	assert.Len(t, got, len(want))
+	for _, a := range got {
+		w, ok := want[a.FY]
+		if assert.True(t, ok, "unexpected FY %s", a.FY) {
+			assert.Equal(t, w, a.Amount.StringFixed(2), a.FY)
+		}
+	}
+}
+
+func TestParseContractPeriod(t *testing.T) {
+	start, end, err := parseContractPeriod("22-Jan-2018 to 31-Oct-2023")
+	assert.NoError(t, err)
+	assert.Equal(t, day("2018-01-22"), start)
+	assert.Equal(t, day("2023-10-31"), end)
+
+	_, _, err = parseContractPeriod("22-Jan-2018")
+	assert.Error(t, err)
+	_, _, err = parseContractPeriod("31-Oct-2023 to 22-Jan-2018")
+	assert.Error(t, err)
+}
+
+func TestAllocateCommittedPartialFirstYear(t *testing.T) {
+	// 730 days at $1/day; 181 days remain in FY 2024-25 from 1 Jan 2025.
+	allocs := allocateCommitted(decimal.NewFromInt(730), day("2024-07-01"), day("2026-06-30"), day("2025-01-01"))
+	assertAllocs(t, map[string]string{"2024-25": "181.00", "2025-26": "365.00"}, allocs)
+}
+
+func TestAllocateCommittedNotYetStarted(t *testing.T) {
+	// Starts mid-year after as-of: whole value is future, split by days.
+	allocs := allocateCommitted(decimal.NewFromInt(366), day("2024-01-01"), day("2024-12-31"), day("2023-07-01"))
+	assertAllocs(t, map[string]string{"2023-24": "182.00", "2024-25": "184.00"}, allocs)
+}
+
+func TestAllocateCommittedEnded(t *testing.T) {
+	assert.Empty(t, allocateCommitted(decimal.NewFromInt(100), day("2020-01-01"), day("2021-01-01"), day("2025-07-01")))
+}
+
+func TestAllocateCommittedEndsOnAsOf(t *testing.T) {
+	allocs := allocateCommitted(decimal.NewFromInt(10), day("2025-06-21"), day("2025-06-30"), day("2025-06-30"))
+	assertAllocs(t, map[string]string{"2024-25": "1.00"}, allocs)
+}
+
+func TestAllocateCommittedLeapYearSumsToValue(t *testing.T) {
+	value := decimal.RequireFromString("1000000")
+	allocs := allocateCommitted(value, day("2023-07-01"), day("2025-03-15"), day("2020-01-01"))
+	sum := decimal.Zero
+	for _, a := range allocs {
+		sum = sum.Add(a.Amount)
+	}
+	assert.Equal(t, value.StringFixed(2), sum.StringFixed(2))
+	assert.Equal(t, "2023-24", allocs[0].FY)
+	assert.Equal(t, "2024-25", allocs[1].FY)
+}
+
+func TestCommittedSpendTable(t *testing.T) {
+	contracts := []*contract{
+		{Agency: "ATO", Contract_Period: "1-Jul-2024 to 30-Jun-2026", Contract_Value: decimal.NewFromInt(730)},
+		{Agency: "ATO", Contract_Period: "1-Jul-2019 to 30-Jun-2020", Contract_Value: decimal.NewFromInt(5)},
+		{Agency: "Defence", Contract_Period: "sometime", Contract_Value: decimal.NewFromInt(5)},
+	}
+	rows, fys, skipped := committedSpend(contracts, day("2025-01-01"), agencyKey)
+	assert.Equal(t, 1, skipped)
+	assert.Equal(t, []string{"2024-25", "2025-26"}, fys)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "546.00", rows[0].Total.StringFixed(2))
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeCommitted(&buf, day("2025-01-01"), rows, fys, skipped, false))
+	assert.Contains(t, buf.String(), "Committed spend as of 2025-01-01")
+	assert.Contains(t, buf.String(), "\nATO  $181.00  $365.00  $546.00\n", "labels are left-aligned, amounts right-aligned")
+	assert.Contains(t, buf.String(), "1 contract(s) skipped")
+	assert.Contains(t, buf.String(), "Assumptions:")
+}
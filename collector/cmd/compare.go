@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+// searchFunc runs a search; scrapeAncap in production, stubs in tests.
+type searchFunc func(searchRequest) (searchResult, error)
+
+// comparisonRow is one line of a side-by-side comparison.
+type comparisonRow struct {
+	Label string
+	Left  decimal.Decimal
+	Right decimal.Decimal
+}
+
+func (r comparisonRow) diff() decimal.Decimal {
+	return r.Right.Sub(r.Left)
+}
+
+// percentDiff is the change from left to right as a percentage of left.
+// It reports false when left is zero and the percentage is undefined.
+func (r comparisonRow) percentDiff() (decimal.Decimal, bool) {
+	if r.Left.IsZero() {
+		return decimal.Zero, false
+	}
+	return r.diff().Div(r.Left).Mul(decimal.NewFromInt(100)), true
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare [agencies|companies|keywords]",
+	Short: "Compare total spend between two agencies, companies or keywords",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		left, _ := cmd.Flags().GetString("left")
+		right, _ := cmd.Flags().GetString("right")
+		by, _ := cmd.Flags().GetString("compare-by")
+		breakdown, _ := cmd.Flags().GetString("breakdown")
+		if len(args) == 1 {
+			by = args[0]
+		}
+		if left == "" || right == "" {
+			return fmt.Errorf("both --left and --right are required")
+		}
+		if breakdown != "" && breakdown != "year" {
+			return fmt.Errorf("unsupported breakdown %q (want year)", breakdown)
+		}
+
+		cfg := resolveConfig(os.Environ())
+		base := requestFromFlags(cmd, cfg)
+		leftReq, rightReq, err := compareRequests(base, by, left, right)
+		if err != nil {
+			return err
+		}
+
+		// Company headings are supplier names; pseudonyms are assigned here,
+		// before the parallel searches, so the sides are labelled in order
+		leftLabel, rightLabel := left, right
+		if by == "company" || by == "companies" {
+			leftLabel, rightLabel = redactor.name(left), redactor.name(right)
+		}
+		summaryFile, _ := cmd.Flags().GetString("summary-file")
+		// Each side records its own summary, so the searches share no state
+		var leftSummary, rightSummary runSummary
+		summarised := func(summary *runSummary) searchFunc {
+			return func(req searchRequest) (searchResult, error) {
+				started := time.Now()
+				res, err := silentSearch(cmd, req)
+				*summary = redactedRunSummary(req, started, res, err)
+				return redactor.result(res), err
+			}
+		}
+		leftRes, rightRes, err := runComparison(summarised(&leftSummary), summarised(&rightSummary), leftReq, rightReq)
+		if summaryFile != "" {
+			// One summary per side, left first
+			if werr := writeRunSummaries(summaryFile, []runSummary{leftSummary, rightSummary}); werr != nil && err == nil {
+				err = werr
+			}
+		}
+		if err != nil {
+			return err
+		}
+		rows := []comparisonRow{}
+		if breakdown == "year" {
+			rows = yearComparisonRows(leftRes.Contracts, rightRes.Contracts)
+		}
+		rows = append(rows, comparisonRow{Label: "Total", Left: leftRes.Total, Right: rightRes.Total})
+		if err := writeComparison(cmd.OutOrStdout(), leftLabel, rightLabel, rows, colourEnabled(cmd.OutOrStdout(), cfg)); err != nil {
+			return err
+		}
+		writeGSTNote(cmd.OutOrStdout(), base.NormaliseGST)
+		return nil
+	},
+}
+
+// compareRequests derives the left and right requests from the shared
+// filters by overriding the field selected by compareBy.
+func compareRequests(base searchRequest, compareBy, left, right string) (searchRequest, searchRequest, error) {
+	l, r := base, base
+	switch compareBy {
+	case "agency", "agencies":
+		l.Agency, r.Agency = left, right
+	case "company", "companies":
+		l.Company, r.Company = left, right
+	case "keyword", "keywords":
+		l.Keyword, r.Keyword = left, right
+	default:
+		return l, r, fmt.Errorf("unsupported compare-by %q (want agency, company or keyword)", compareBy)
+	}
+	return l, r, nil
+}
+
+// runComparison runs searchLeft on left and searchRight on right in
+// parallel.
+func runComparison(searchLeft, searchRight searchFunc, left, right searchRequest) (searchResult, searchResult, error) {
+	var wg sync.WaitGroup
+	var leftRes, rightRes searchResult
+	var leftErr, rightErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		leftRes, leftErr = searchLeft(left)
+	}()
+	go func() {
+		defer wg.Done()
+		rightRes, rightErr = searchRight(right)
+	}()
+	wg.Wait()
+	if leftErr != nil {
+		return leftRes, rightRes, leftErr
+	}
+	return leftRes, rightRes, rightErr
+}
+
+// totalsByFY sums contract values per financial year of their publish date.
+func totalsByFY(contracts []*contract) map[string]decimal.Decimal {
+	totals := map[string]decimal.Decimal{}
+	for _, g := range groupContracts(contracts, fyKey) {
+		totals[g.Key] = g.Total
+	}
+	return totals
+}
+
+func yearComparisonRows(left, right []*contract) []comparisonRow {
+	leftFY, rightFY := totalsByFY(left), totalsByFY(right)
+	labels := []string{}
+	for fy := range leftFY {
+		labels = append(labels, fy)
+	}
+	for fy := range rightFY {
+		if _, ok := leftFY[fy]; !ok {
+			labels = append(labels, fy)
+		}
+	}
+	sort.Strings(labels)
+	rows := []comparisonRow{}
+	for _, fy := range labels {
+		rows = append(rows, comparisonRow{Label: fy, Left: leftFY[fy], Right: rightFY[fy]})
+	}
+	return rows
+}
+
+// writeComparison prints the comparison table, colouring the difference
+// green for increases and red for decreases on a terminal.
+func writeComparison(w io.Writer, leftName, rightName string, rows []comparisonRow, colour bool) error {
+	t := newTextTable(
+		tableColumn{},
+		tableColumn{Heading: leftName, Right: true},
+		tableColumn{Heading: rightName, Right: true},
+		tableColumn{Heading: "Difference", Right: true},
+		tableColumn{Heading: "Change", Right: true},
+	)
+	for _, r := range rows {
+		pct := "n/a"
+		if p, ok := r.percentDiff(); ok {
+			pct = p.StringFixed(1) + "%"
+		}
+		style := ""
+		switch r.diff().Sign() {
+		case 1:
+			style = ansiGreen
+		case -1:
+			style = ansiRed
+		}
+		t.addRow(
+			tableCell{Text: r.Label},
+			tableCell{Text: formatMoney(r.Left)},
+			tableCell{Text: formatMoney(r.Right)},
+			tableCell{Text: formatMoney(r.diff()), Style: style},
+			tableCell{Text: pct, Style: style},
+		)
+	}
+	return t.render(w, colour)
+}
+
+func init() {
+	compareCmd.Flags().String("left", "", "Left-hand entity to compare")
+	compareCmd.Flags().String("right", "", "Right-hand entity to compare")
+	compareCmd.Flags().String("compare-by", "agency", "Field to compare on: agency, company or keyword")
+	compareCmd.Flags().String("breakdown", "", "Add a per-period breakdown: year")
+	rootCmd.AddCommand(compareCmd)
+}
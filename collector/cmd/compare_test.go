@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareRequests(t *testing.T) {
+	base := searchRequest{Keyword: "audit"}
+	l, r, err := compareRequests(base, "agency", "ATO", "Home Affairs")
+	assert.NoError(t, err)
+	assert.Equal(t, searchRequest{Keyword: "audit", Agency: "ATO"}, l)
+	assert.Equal(t, searchRequest{Keyword: "audit", Agency: "Home Affairs"}, r)
+
+	_, _, err = compareRequests(base, "supplier", "a", "b")
+	assert.Error(t, err)
+}
+
+func TestRunComparison(t *testing.T) {
+	stub := func(req searchRequest) (searchResult, error) {
+		switch req.Agency {
+		case "ATO":
+			return searchResult{Total: decimal.NewFromInt(200), Contracts: []*contract{
+				{Publish_Date: "6-Feb-2018", Contract_Value: decimal.NewFromInt(150)},
+				{Publish_Date: "1-Jul-2018", Contract_Value: decimal.NewFromInt(50)},
+			}}, nil
+		default:
+			return searchResult{Total: decimal.NewFromInt(250), Contracts: []*contract{
+				{Publish_Date: "30-Jun-2018", Contract_Value: decimal.NewFromInt(250)},
+			}}, nil
+		}
+	}
+	left, right, err := runComparison(stub, stub, searchRequest{Agency: "ATO"}, searchRequest{Agency: "Home Affairs"})
+	assert.NoError(t, err)
+
+	rows := yearComparisonRows(left.Contracts, right.Contracts)
+	assert.Equal(t, []string{"2017-18", "2018-19"}, []string{rows[0].Label, rows[1].Label})
+	assert.True(t, rows[0].diff().Equal(decimal.NewFromInt(100)))
+	assert.True(t, rows[1].diff().Equal(decimal.NewFromInt(-50)))
+
+	total := comparisonRow{Label: "Total", Left: left.Total, Right: right.Total}
+	pct, ok := total.percentDiff()
+	assert.True(t, ok)
+	assert.Equal(t, "25.0", pct.StringFixed(1))
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeComparison(&buf, "ATO", "Home Affairs", append(rows, total), false))
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 4)
+	assert.Equal(t, []string{"ATO", "Home", "Affairs", "Difference", "Change"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"2017-18", "$150.00", "$250.00", "$100.00", "66.7%"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"2018-19", "$50.00", "$0.00", "-$50.00", "-100.0%"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"Total", "$200.00", "$250.00", "$50.00", "25.0%"}, strings.Fields(lines[3]))
+}
+
+func TestPercentDiffUndefinedForZeroLeft(t *testing.T) {
+	_, ok := comparisonRow{Right: decimal.NewFromInt(1)}.percentDiff()
+	assert.False(t, ok)
+}
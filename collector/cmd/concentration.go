@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+// concentration measures how concentrated spend is among suppliers.
+type concentration struct {
+	FY        string          `json:"fy"`
+	Spend     decimal.Decimal `json:"spend"`
+	Suppliers int             `json:"suppliers"`
+	// Herfindahl-Hirschman index: the sum of squared percentage shares,
+	// from near 0 (fragmented) to 10000 (a single supplier)
+	HHI decimal.Decimal `json:"hhi"`
+	// Percentage of spend going to the largest and five largest suppliers
+	Top1Share   decimal.Decimal `json:"top1Share"`
+	Top5Share   decimal.Decimal `json:"top5Share"`
+	TopSupplier string          `json:"topSupplier"`
+}
+
+// supplierConcentration computes concentration metrics for one set of
+// contracts from its supplier breakdown.
+func supplierConcentration(fy string, contracts []*contract) concentration {
+	groups := groupContracts(contracts, supplierKey)
+	sortByTotalDesc(groups)
+	c := concentration{FY: fy, Suppliers: len(groups)}
+	for _, g := range groups {
+		c.Spend = c.Spend.Add(g.Total)
+	}
+	if c.Spend.IsZero() {
+		return c
+	}
+	hundred := decimal.NewFromInt(100)
+	for i, g := range groups {
+		share := g.Total.Div(c.Spend).Mul(hundred)
+		c.HHI = c.HHI.Add(share.Mul(share))
+		if i == 0 {
+			c.Top1Share = share
+			c.TopSupplier = g.Key
+		}
+		if i < 5 {
+			c.Top5Share = c.Top5Share.Add(share)
+		}
+	}
+	c.HHI = c.HHI.Round(0)
+	c.Top1Share = c.Top1Share.Round(1)
+	c.Top5Share = c.Top5Share.Round(1)
+	return c
+}
+
+// concentrationByFY returns one row per requested financial year, or per
+// year present when fys is empty.
+func concentrationByFY(contracts []*contract, fys []string) []concentration {
+	if len(fys) == 0 {
+		for _, g := range groupContracts(contracts, fyKey) {
+			fys = append(fys, g.Key)
+		}
+	}
+	rows := []concentration{}
+	for _, fy := range fys {
+		rows = append(rows, supplierConcentration(fy, filterFY(contracts, fy)))
+	}
+	return rows
+}
+
+func writeConcentration(w io.Writer, rows []concentration, colour bool) error {
+	t := newTextTable(
+		tableColumn{Heading: "FY"},
+		tableColumn{Heading: "Spend", Right: true},
+		tableColumn{Heading: "Suppliers", Right: true},
+		tableColumn{Heading: "HHI", Right: true},
+		tableColumn{Heading: "Top 1", Right: true},
+		tableColumn{Heading: "Top 5", Right: true},
+		tableColumn{Heading: "Top supplier"},
+	)
+	for _, r := range rows {
+		t.addRow(plainCells(r.FY, formatMoney(r.Spend), fmt.Sprint(r.Suppliers), r.HHI.String(),
+			r.Top1Share.StringFixed(1)+"%", r.Top5Share.StringFixed(1)+"%", r.TopSupplier)...)
+	}
+	return t.render(w, colour)
+}
+
+var concentrationCmd = &cobra.Command{
+	Use:   "concentration",
+	Short: "Supplier concentration (HHI, top-1 and top-5 share) of an agency's spend",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fyFlags, _ := cmd.Flags().GetStringSlice("fy")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		fys := []string{}
+		for _, fy := range fyFlags {
+			start, err := parseFYLabel(fy)
+			if err != nil {
+				return err
+			}
+			fys = append(fys, fyLabelFromStart(start))
+		}
+		cfg := resolveConfig(os.Environ())
+		req := requestFromFlags(cmd, cfg)
+		if req.Agency == "" && req.Portfolio == "" {
+			return fmt.Errorf("an agency (--d) or portfolio (--portfolio) is required")
+		}
+
+		res, err := quietSearch(cmd, req)
+		if err != nil {
+			return err
+		}
+		rows := concentrationByFY(res.Contracts, fys)
+		if asJSON {
+			writeGSTNote(cmd.ErrOrStderr(), req.NormaliseGST)
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(rows)
+		}
+		if err := writeConcentration(cmd.OutOrStdout(), rows, colourEnabled(cmd.OutOrStdout(), cfg)); err != nil {
+			return err
+		}
+		writeGSTNote(cmd.OutOrStdout(), req.NormaliseGST)
+		return nil
+	},
+}
+
+func init() {
+	concentrationCmd.Flags().StringSlice("fy", nil, "Financial years to measure, e.g. 2022-23,2023-24 (default every year found)")
+	concentrationCmd.Flags().Bool("json", false, "Print results as JSON")
+	rootCmd.AddCommand(concentrationCmd)
+}
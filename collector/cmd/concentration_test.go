@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupplierConcentration(t *testing.T) {
+	// Shares of 50%, 30%, 10%, 5%, 3% and 2%:
+	// HHI = 2500 + 900 + 100 + 25 + 9 + 4 = 3538
+	contracts := []*contract{}
+	for supplier, value := range map[string]int64{"A": 500, "B": 300, "C": 100, "D": 50, "E": 30, "F": 20} {
+		contracts = append(contracts, &contract{Supplier_Name: supplier, Publish_Date: "1-Aug-2023", Contract_Value: decimal.NewFromInt(value)})
+	}
+	c := supplierConcentration("2023-24", contracts)
+	assert.Equal(t, "3538", c.HHI.String())
+	assert.Equal(t, "50.0", c.Top1Share.StringFixed(1))
+	assert.Equal(t, "98.0", c.Top5Share.StringFixed(1))
+	assert.Equal(t, "A", c.TopSupplier)
+	assert.Equal(t, 6, c.Suppliers)
+
+	single := supplierConcentration("", contracts[:1])
+	assert.Equal(t, "10000", single.HHI.String(), "a single supplier is maximally concentrated")
+
+	empty := supplierConcentration("", nil)
+	assert.True(t, empty.HHI.IsZero())
+}
+
+func TestConcentrationByFY(t *testing.T) {
+	contracts := []*contract{
+		{Supplier_Name: "A", Publish_Date: "1-Aug-2022", Contract_Value: decimal.NewFromInt(50)},
+		{Supplier_Name: "B", Publish_Date: "1-Aug-2022", Contract_Value: decimal.NewFromInt(50)},
+		{Supplier_Name: "A", Publish_Date: "1-Aug-2023", Contract_Value: decimal.NewFromInt(90)},
+	}
+	rows := concentrationByFY(contracts, nil)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "5000", rows[0].HHI.String())
+	assert.Equal(t, "10000", rows[1].HHI.String())
+
+	rows = concentrationByFY(contracts, []string{"2023-24"})
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "2023-24", rows[0].FY)
+}
+
+func TestConcentrationCommand(t *testing.T) {
+	base := serveFixtures(t)
+	out, _, err := runRoot(t, "concentration", "--base-url", base, "--c", "KPMG", "--d", "Defence", "--fy", "2018-2019", "--no-progress")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "2018-19")
+
+	_, _, err = runRoot(t, "concentration", "--base-url", base)
+	assert.Error(t, err, "an agency is required")
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import "strings"
+
+// config holds the settings taken from the environment. Each command
+// resolves it once at the start of its run and passes it on, so nothing
+// else reads the environment, and tests can build one directly instead of
+// calling t.Setenv.
+type config struct {
+	// BaseURL is the site to search when --base-url is unset
+	BaseURL string
+	// NoColor disables styled tables (https://no-color.org)
+	NoColor bool
+	// OpenSearch credentials, kept out of flags and shell history
+	OpenSearchUser     string
+	OpenSearchPassword string
+	OpenSearchAPIKey   string
+}
+
+// resolveConfig builds the configuration from environ, a list of
+// "KEY=value" entries as returned by os.Environ.
+func resolveConfig(environ []string) config {
+	env := map[string]string{}
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	return config{
+		BaseURL:            env[baseURLEnv],
+		NoColor:            env["NO_COLOR"] != "",
+		OpenSearchUser:     env[openSearchUserEnv],
+		OpenSearchPassword: env[openSearchPasswordEnv],
+		OpenSearchAPIKey:   env[openSearchAPIKeyEnv],
+	}
+}
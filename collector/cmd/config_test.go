@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveConfig(t *testing.T) {
+	c := resolveConfig([]string{
+		baseURLEnv + "=https://staging.example",
+		"NO_COLOR=1",
+		openSearchUserEnv + "=admin",
+		openSearchPasswordEnv + "=a=b",
+		"UNRELATED=x",
+		"MALFORMED",
+	})
+	assert.Equal(t, config{
+		BaseURL:            "https://staging.example",
+		NoColor:            true,
+		OpenSearchUser:     "admin",
+		OpenSearchPassword: "a=b",
+	}, c)
+
+	assert.Equal(t, config{}, resolveConfig(nil))
+	assert.False(t, resolveConfig([]string{"NO_COLOR="}).NoColor, "empty NO_COLOR leaves colour on")
+}
+
+func TestRootUsesBaseURLFromEnvironment(t *testing.T) {
+	base := serveFixtures(t)
+	t.Setenv(baseURLEnv, base)
+	out, _, err := runRoot(t, "--c", "KPMG", "--output", "quiet")
+	assert.NoError(t, err)
+	assert.Equal(t, "742560.50\n", out)
+}
+
+func TestRequestFromFlagsPrefersBaseURLFlag(t *testing.T) {
+	resetFlags(rootCmd)
+	cfg := config{BaseURL: "https://env.example"}
+	assert.Equal(t, "https://env.example", requestFromFlags(rootCmd, cfg).BaseURL)
+
+	rootCmd.PersistentFlags().Set("base-url", "https://flag.example")
+	t.Cleanup(func() { resetFlags(rootCmd) })
+	assert.Equal(t, "https://flag.example", requestFromFlags(rootCmd, cfg).BaseURL)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// writeAmendmentHistory prints each notice of one contract with the change
+// in value it made.
+func writeAmendmentHistory(w io.Writer, history []*contract, colour bool) error {
+	t := newTextTable(
+		tableColumn{Heading: "Notice"},
+		tableColumn{Heading: "Published"},
+		tableColumn{Heading: "Value", Right: true},
+		tableColumn{Heading: "Change", Right: true},
+	)
+	for i, n := range history {
+		change := ""
+		if i > 0 {
+			change = formatMoney(n.Contract_Value.Sub(history[i-1].Contract_Value))
+		}
+		t.addRow(plainCells(n.CN_ID, n.Publish_Date, formatMoney(n.Contract_Value), change)...)
+	}
+	return t.render(w, colour)
+}
+
+var contractCmd = &cobra.Command{
+	Use:   "contract <id>",
+	Short: "Show a contract at its latest value, or every amendment with --history",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		showHistory, _ := cmd.Flags().GetBool("history")
+		id := canonicalContractID(args[0])
+		cfg := resolveConfig(os.Environ())
+		req := requestFromFlags(cmd, cfg)
+		if req.Keyword == "" {
+			req.Keyword = id
+		}
+		req.TrackAmendments = true
+
+		res, err := quietSearch(cmd, req)
+		if err != nil {
+			return err
+		}
+		history := res.History[id]
+		if len(history) == 0 {
+			return fmt.Errorf("no notices found for contract %s", id)
+		}
+		latest := history[len(history)-1]
+		if !showHistory {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s (%s) %s -> %s: %s\n", latest.CN_ID, latest.Publish_Date, latest.Agency, latest.Supplier_Name, formatMoney(latest.Contract_Value))
+			return nil
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: %s -> %s\n", id, latest.Agency, latest.Supplier_Name)
+		return writeAmendmentHistory(cmd.OutOrStdout(), history, colourEnabled(cmd.OutOrStdout(), cfg))
+	},
+}
+
+func init() {
+	contractCmd.Flags().Bool("history", false, "List every notice for the contract, original first")
+	rootCmd.AddCommand(contractCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// resultTopSuppliers is how many suppliers a searchResult ranks.
+const resultTopSuppliers = 10
+
+// supplierTotal is one supplier's share of a search result.
+type supplierTotal struct {
+	Name  string          `json:"name"`
+	Total decimal.Decimal `json:"total"`
+	Count int             `json:"count"`
+}
+
+// publishRange is the span of publish dates among a result's contracts, as
+// YYYY-MM-DD.
+type publishRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// topSuppliers ranks suppliers by total value, largest first, keeping n.
+func topSuppliers(contracts []*contract, n int) []supplierTotal {
+	return rankSuppliers(contracts, supplierKey, n)
+}
+
+// rankSuppliers is topSuppliers with supplier names grouped by key.
+func rankSuppliers(contracts []*contract, key func(*contract) string, n int) []supplierTotal {
+	groups := groupContracts(contracts, key)
+	sortByTotalDesc(groups)
+	if n > 0 && len(groups) > n {
+		groups = groups[:n]
+	}
+	top := make([]supplierTotal, len(groups))
+	for i, g := range groups {
+		top[i] = supplierTotal{Name: g.Key, Total: g.Total, Count: g.Count}
+	}
+	return top
+}
+
+// publishDateRange returns the earliest and latest parseable publish dates,
+// or nil when no contract has one.
+func publishDateRange(contracts []*contract) *publishRange {
+	var from, to time.Time
+	for _, c := range contracts {
+		t, err := parsePublishDate(c.Publish_Date)
+		if err != nil {
+			continue
+		}
+		if from.IsZero() || t.Before(from) {
+			from = t
+		}
+		if t.After(to) {
+			to = t
+		}
+	}
+	if from.IsZero() {
+		return nil
+	}
+	return &publishRange{From: from.Format(time.DateOnly), To: to.Format(time.DateOnly)}
+}
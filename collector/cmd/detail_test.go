@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopSuppliers(t *testing.T) {
+	contracts := []*contract{
+		{Supplier_Name: "B", Contract_Value: decimal.NewFromInt(10)},
+		{Supplier_Name: "A", Contract_Value: decimal.NewFromInt(30)},
+		{Supplier_Name: "B", Contract_Value: decimal.NewFromInt(25)},
+		{Supplier_Name: "C", Contract_Value: decimal.NewFromInt(1)},
+	}
+	top := topSuppliers(contracts, 2)
+	assert.Len(t, top, 2)
+	assert.Equal(t, "B", top[0].Name)
+	assert.True(t, top[0].Total.Equal(decimal.NewFromInt(35)))
+	assert.Equal(t, 2, top[0].Count)
+	assert.Equal(t, "A", top[1].Name)
+}
+
+func TestPublishDateRange(t *testing.T) {
+	assert.Nil(t, publishDateRange([]*contract{{Publish_Date: "garbage"}}))
+	r := publishDateRange([]*contract{{Publish_Date: "3-Aug-2018"}, {Publish_Date: ""}, {Publish_Date: "6-Feb-2018"}, {Publish_Date: "15-Mar-2019"}})
+	assert.Equal(t, &publishRange{From: "2018-02-06", To: "2019-03-15"}, r)
+}
+
+func TestScrapeAncapReturnsDetail(t *testing.T) {
+	base := serveFixtures(t)
+
+	res, err := scrapeAncap(searchRequest{BaseURL: base, Company: "KPMG"}, &recordingSink{})
+	assert.NoError(t, err)
+	assert.Len(t, res.Contracts, 3)
+	assert.Equal(t, "KPMG Peat Marwick - ACT", res.TopSuppliers[0].Name)
+	assert.True(t, res.TopSuppliers[0].Total.Equal(decimal.NewFromInt(542560)))
+	assert.Equal(t, &publishRange{From: "2018-02-06", To: "2019-03-15"}, res.DateRange)
+}
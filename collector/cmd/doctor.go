@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/spf13/cobra"
+)
+
+// selectorCheck is a structural element a source's result page must contain.
+type selectorCheck struct {
+	Name     string
+	Selector string
+	// Text expected on at least one element matching Selector, if set
+	Text string
+}
+
+// sourceCheck describes a live self-test for one source: a query with
+// stable results and the page structure the scraper depends on.
+type sourceCheck struct {
+	Source string
+	Query  searchRequest
+	Checks []selectorCheck
+}
+
+// doctorChecks lists a self-test per source. Adding a source means adding
+// its checks here.
+var doctorChecks = []sourceCheck{
+	{
+		Source: "federal",
+		Query:  searchRequest{Company: "KPMG"},
+		Checks: []selectorCheck{
+			{Name: "result blocks", Selector: ".col-sm-8"},
+			{Name: "field rows", Selector: ".col-sm-8 .list-desc"},
+			{Name: "field values", Selector: ".list-desc .list-desc-inner"},
+			{Name: "CN ID label", Selector: ".list-desc span", Text: "CN ID:"},
+			{Name: "agency label", Selector: ".list-desc span", Text: "Agency:"},
+			{Name: "value label", Selector: ".list-desc span", Text: "Contract Value (AUD):"},
+			{Name: "supplier label", Selector: ".list-desc span", Text: "Supplier Name:"},
+		},
+	},
+}
+
+// checkResult is the outcome of one selectorCheck.
+type checkResult struct {
+	Source string
+	Check  selectorCheck
+	Err    error
+}
+
+// runSourceCheck fetches the check's query page from base and verifies
+// each selector.
+func runSourceCheck(client *http.Client, base string, sc sourceCheck) []checkResult {
+	resp, err := client.Get(searchURL(base, sc.Query))
+	if err == nil && resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var doc *goquery.Document
+	if err == nil {
+		doc, err = goquery.NewDocumentFromReader(resp.Body)
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	results := []checkResult{}
+	for _, check := range sc.Checks {
+		r := checkResult{Source: sc.Source, Check: check, Err: err}
+		if err == nil {
+			r.Err = verifySelector(doc, check)
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// verifySelector checks that check.Selector matches, and when check.Text is
+// set that some match has that text. Text is trimmed first, as the scraper's
+// ChildText does, so reformatted markup doesn't fail the check.
+func verifySelector(doc *goquery.Document, check selectorCheck) error {
+	found := doc.Find(check.Selector)
+	if found.Length() == 0 {
+		return fmt.Errorf("selector %q matched nothing", check.Selector)
+	}
+	if check.Text == "" {
+		return nil
+	}
+	hasText := found.FilterFunction(func(_ int, s *goquery.Selection) bool {
+		return strings.TrimSpace(s.Text()) == check.Text
+	}).Length() > 0
+	if !hasText {
+		return fmt.Errorf("selector %q has no element with text %q", check.Selector, check.Text)
+	}
+	return nil
+}
+
+// writeCheckResults prints pass/fail per check and returns the failure count.
+func writeCheckResults(w io.Writer, results []checkResult) int {
+	failures := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failures++
+			fmt.Fprintf(w, "FAIL %s: %s: %v\n", r.Source, r.Check.Name, r.Err)
+			continue
+		}
+		fmt.Fprintf(w, "PASS %s: %s\n", r.Source, r.Check.Name)
+	}
+	return failures
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check each source's page structure against live pages",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, _ := cmd.Flags().GetString("source")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		client := &http.Client{Timeout: timeout}
+		cfg := resolveConfig(os.Environ())
+		base, err := resolveBaseURL(requestFromFlags(cmd, cfg).BaseURL)
+		if err != nil {
+			return err
+		}
+
+		results := []checkResult{}
+		for _, sc := range doctorChecks {
+			if source != "" && sc.Source != source {
+				continue
+			}
+			results = append(results, runSourceCheck(client, base, sc)...)
+		}
+		if len(results) == 0 {
+			return fmt.Errorf("no checks for source %q", source)
+		}
+		if failures := writeCheckResults(cmd.OutOrStdout(), results); failures > 0 {
+			return fmt.Errorf("%d check(s) failed", failures)
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().String("source", "", "Only check this source")
+	doctorCmd.Flags().Duration("timeout", 30*time.Second, "Timeout per source request")
+	rootCmd.AddCommand(doctorCmd)
+}
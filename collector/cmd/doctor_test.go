@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoctorPassesOnFixture(t *testing.T) {
+	base := serveFixtures(t)
+	results := runSourceCheck(http.DefaultClient, base, doctorChecks[0])
+
+	var buf bytes.Buffer
+	assert.Equal(t, 0, writeCheckResults(&buf, results))
+	assert.Contains(t, buf.String(), "PASS federal: result blocks")
+}
+
+func TestDoctorReportsBrokenSelector(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><div class="col-sm-8"><div class="list-desc"><span>CN Number:</span><div class="list-desc-inner">CN1</div></div></div></body></html>`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var buf bytes.Buffer
+	failures := writeCheckResults(&buf, runSourceCheck(http.DefaultClient, srv.URL, doctorChecks[0]))
+	assert.Equal(t, 4, failures)
+	assert.Contains(t, buf.String(), `FAIL federal: CN ID label: selector ".list-desc span" has no element with text "CN ID:"`)
+}
+
+func TestDoctorIgnoresSurroundingWhitespace(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := os.ReadFile(filepath.Join("testdata", "cn_search_page1.html"))
+		w.Write(bytes.ReplaceAll(data, []byte("<span>CN ID:</span>"), []byte("<span>\n      CN ID:\n    </span>")))
+	}))
+	t.Cleanup(srv.Close)
+
+	var buf bytes.Buffer
+	assert.Equal(t, 0, writeCheckResults(&buf, runSourceCheck(http.DefaultClient, srv.URL, doctorChecks[0])), buf.String())
+	assert.Contains(t, buf.String(), "PASS federal: CN ID label")
+}
+
+func TestDoctorCommandExitsNonZero(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "blocked", http.StatusForbidden)
+	}))
+	t.Cleanup(srv.Close)
+
+	out, _, err := runRoot(t, "doctor", "--source", "federal", "--base-url", srv.URL)
+	assert.EqualError(t, err, "7 check(s) failed")
+	assert.Contains(t, out, "unexpected status 403 Forbidden")
+
+	_, _, err = runRoot(t, "doctor", "--source", "qld")
+	assert.EqualError(t, err, `no checks for source "qld"`)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os"
+	"time"
+)
+
+// partialSuffix marks an export that has not completed successfully.
+const partialSuffix = ".partial"
+
+// exportSyncInterval bounds how much written output an fsync can lose.
+var exportSyncInterval = time.Second
+
+// exportFile streams output to path+".partial", fsyncing periodically, and
+// only renames it to path on Commit. A run that fails or dies leaves the
+// clearly-marked partial file behind instead of a truncated export.
+type exportFile struct {
+	f        *os.File
+	path     string
+	lastSync time.Time
+	// First write error; sinks don't check theirs, so Commit does
+	err error
+}
+
+func createExportFile(path string) (*exportFile, error) {
+	f, err := os.Create(path + partialSuffix)
+	if err != nil {
+		return nil, err
+	}
+	return &exportFile{f: f, path: path, lastSync: time.Now()}, nil
+}
+
+func (e *exportFile) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.f.Write(p)
+	if err == nil && time.Since(e.lastSync) >= exportSyncInterval {
+		err = e.f.Sync()
+		e.lastSync = time.Now()
+	}
+	e.err = err
+	return n, err
+}
+
+// Commit flushes the export to disk and moves it to its final path. After
+// a failed write it returns that error and leaves the partial file.
+func (e *exportFile) Commit() error {
+	if e.err != nil {
+		e.f.Close()
+		return e.err
+	}
+	if err := e.f.Sync(); err != nil {
+		e.f.Close()
+		return err
+	}
+	if err := e.f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(e.f.Name(), e.path)
+}
+
+// Abort closes the export, leaving it at its .partial path.
+func (e *exportFile) Abort() error {
+	return e.f.Close()
+}
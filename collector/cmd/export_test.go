@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportFileCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	e, err := createExportFile(path)
+	assert.NoError(t, err)
+	fmt.Fprintln(e, "row")
+	assert.NoFileExists(t, path, "nothing at the final path before commit")
+
+	assert.NoError(t, e.Commit())
+	assert.FileExists(t, path)
+	assert.NoFileExists(t, path+partialSuffix)
+}
+
+func TestExportFileCrashLeavesPartial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	e, err := createExportFile(path)
+	assert.NoError(t, err)
+	sink, _ := newOutputSink("csv", e, nil, nil)
+	sink.OnMatch(sampleContract)
+	// The run dies here: no OnTotal, no Commit.
+	assert.NoError(t, e.Abort())
+
+	assert.NoFileExists(t, path)
+	data, err := os.ReadFile(path + partialSuffix)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "CN3482539", "rows are flushed as they are matched")
+}
+
+func TestExportFileWriteErrorBlocksCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	e, err := createExportFile(path)
+	assert.NoError(t, err)
+	// A read-only handle makes every write fail, as a full disk would
+	assert.NoError(t, e.f.Close())
+	e.f, err = os.Open(path + partialSuffix)
+	assert.NoError(t, err)
+
+	for _, format := range []string{"csv", "jsonl"} {
+		sink, _ := newOutputSink(format, e, nil, nil)
+		sink.OnMatch(sampleContract)
+		sink.OnTotal(searchTotals{}, "")
+	}
+	assert.Error(t, e.Commit())
+	assert.NoFileExists(t, path, "a failed export is never renamed into place")
+	assert.FileExists(t, path+partialSuffix)
+}
+
+func TestRootOutFile(t *testing.T) {
+	base := serveFixtures(t)
+	path := filepath.Join(t.TempDir(), "kpmg.jsonl")
+	out, _, err := runRoot(t, "--base-url", base, "--c", "KPMG", "--output", "jsonl", "--out-file", path, "--no-progress")
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 4)
+	assert.Contains(t, lines[3], `"event":"total"`, "the total event is the footer")
+}
+
+func TestRootCSVExportWithShorthandFlags(t *testing.T) {
+	base := serveFixtures(t)
+	path := filepath.Join(t.TempDir(), "kpmg.csv")
+	_, _, err := runRoot(t, "--base-url", base, "-c", "KPMG", "-d", "Defence", "--output", "csv", "--out-file", path)
+	assert.NoError(t, err)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 3, "header and the two Defence contracts")
+}
+
+func TestRootOutFileFailedRunStaysPartial(t *testing.T) {
+	base := serveFixtures(t)
+	path := filepath.Join(t.TempDir(), "kpmg.csv")
+	_, _, err := runRoot(t, "--base-url", base, "--c", "KPMG", "--output", "csv", "--out-file", path, "--normalise-gst", "bogus")
+	assert.Error(t, err)
+	assert.NoFileExists(t, path)
+	assert.FileExists(t, path+partialSuffix)
+}
+
+// BenchmarkCSVExportMillionRows streams 1M contracts through the csv sink to
+// an export file and reports peak heap use. This covers the export path
+// only, which stays flat regardless of row count; a real scrape also keeps
+// every match to aggregate amendments, see BenchmarkScrapeCSVExport.
+func BenchmarkCSVExportMillionRows(b *testing.B) {
+	const rows = 1000000
+	for i := 0; i < b.N; i++ {
+		e, err := createExportFile(filepath.Join(b.TempDir(), "out.csv"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		sink, _ := newOutputSink("csv", e, nil, nil)
+		c := *sampleContract
+		var peak uint64
+		var ms runtime.MemStats
+		for r := 0; r < rows; r++ {
+			c.CN_ID = fmt.Sprintf("CN%d", r)
+			sink.OnMatch(&c)
+			if r%100000 == 0 {
+				runtime.ReadMemStats(&ms)
+				if ms.HeapInuse > peak {
+					peak = ms.HeapInuse
+				}
+			}
+		}
+		sink.OnTotal(searchTotals{}, "")
+		if err := e.Commit(); err != nil {
+			b.Fatal(err)
+		}
+		b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+	}
+}
+
+// heapSampler passes matches to an OutputSink, sampling heap use every
+// 1000 matches.
+type heapSampler struct {
+	OutputSink
+	matches int
+	peak    uint64
+}
+
+func (s *heapSampler) OnMatch(c *contract) {
+	s.OutputSink.OnMatch(c)
+	if s.matches++; s.matches%1000 == 0 {
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		if ms.HeapInuse > s.peak {
+			s.peak = ms.HeapInuse
+		}
+	}
+}
+
+// serveGeneratedPages serves pages result pages of perPage contracts each.
+// The first page links to the rest.
+func serveGeneratedPages(b *testing.B, pages, perPage int) string {
+	b.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("Page"))
+		if page == 0 {
+			page = 1
+		}
+		var sb strings.Builder
+		sb.WriteString("<html><body>\n")
+		if page == 1 {
+			for p := 2; p <= pages; p++ {
+				fmt.Fprintf(&sb, `<a href="/Search/CnAdvancedSearch?SupplierName=&Page=%d">%d</a>`+"\n", p, p)
+			}
+		}
+		for i := 0; i < perPage; i++ {
+			fmt.Fprintf(&sb, `<div class="col-sm-8">
+<div class="list-desc"><span>CN ID:</span><div class="list-desc-inner">CN%d</div></div>
+<div class="list-desc"><span>Agency:</span><div class="list-desc-inner">Department of Defence</div></div>
+<div class="list-desc"><span>Publish Date:</span><div class="list-desc-inner">15-Mar-2019</div></div>
+<div class="list-desc"><span>Contract Value (AUD):</span><div class="list-desc-inner">$1,000.00</div></div>
+<div class="list-desc"><span>Supplier Name:</span><div class="list-desc-inner">KPMG Australia</div></div>
+</div>
+`, page*perPage+i)
+		}
+		sb.WriteString("</body></html>\n")
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, sb.String())
+	}))
+	b.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// BenchmarkScrapeCSVExport runs a whole scrape of 100k contracts into a csv
+// export and reports peak heap use. Unlike the sink alone this grows with
+// the result size, since the scrape keeps every match to aggregate
+// amendments into totals.
+func BenchmarkScrapeCSVExport(b *testing.B) {
+	base := serveGeneratedPages(b, 100, 1000)
+	for i := 0; i < b.N; i++ {
+		e, err := createExportFile(filepath.Join(b.TempDir(), "out.csv"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		sink, _ := newOutputSink("csv", e, nil, nil)
+		sampler := &heapSampler{OutputSink: sink}
+		res, err := scrapeAncap(searchRequest{BaseURL: base}, sampler)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := e.Commit(); err != nil {
+			b.Fatal(err)
+		}
+		b.ReportMetric(float64(sampler.peak)/(1<<20), "peak-heap-MB")
+		b.ReportMetric(float64(len(res.Contracts)), "contracts")
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whatnick/austender_analyser/collector/parse"
+)
+
+// parsePublishDate parses AusTender dates such as "6-Feb-2018".
+func parsePublishDate(s string) (time.Time, error) {
+	return parse.ParseDate(s, parse.AusTenderDateLayouts...)
+}
+
+// financialYearLabel returns the Australian financial year (July to June)
+// containing t, e.g. "2017-18" for 6 Feb 2018.
+func financialYearLabel(t time.Time) (string, error) {
+	if t.IsZero() {
+		return "", parse.ErrZeroDate
+	}
+	start := t.Year()
+	if t.Month() < time.July {
+		start--
+	}
+	return fyLabelFromStart(start), nil
+}
+
+// fyLabelFromStart labels the financial year starting in July of start,
+// e.g. "1999-00" for 1999. The four-digit start year keeps labels in
+// chronological order when sorted as strings, even across centuries.
+func fyLabelFromStart(start int) string {
+	return fmt.Sprintf("%d-%02d", start, (start+1)%100)
+}
+
+var fyLabelPattern = regexp.MustCompile(`^(\d{4})-(\d{2}|\d{4})$`)
+
+// parseFYLabel returns the start year of a financial year written as
+// "2023-24" or "2023-2024". The two years must be consecutive, so
+// "1999-00" and "2099-2100" parse but "2023-25" does not.
+func parseFYLabel(s string) (int, error) {
+	m := fyLabelPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid financial year %q (want e.g. 2023-24)", s)
+	}
+	start, _ := strconv.Atoi(m[1])
+	end, _ := strconv.Atoi(m[2])
+	if len(m[2]) == 2 {
+		end += (start + 1) / 100 * 100
+	}
+	if end != start+1 {
+		return 0, fmt.Errorf("invalid financial year %q: years must be consecutive", s)
+	}
+	return start, nil
+}
+
+// monthLabel returns the calendar month of t, e.g. "2018-02".
+func monthLabel(t time.Time) (string, error) {
+	if t.IsZero() {
+		return "", parse.ErrZeroDate
+	}
+	return t.Format("2006-01"), nil
+}
+
+// undatedWarning reports notices whose publish date could not be parsed, or
+// "" when every date parsed. Such contracts still count towards totals but
+// fall under "unknown" in date breakdowns.
+func undatedWarning(contracts []*contract) string {
+	undated := 0
+	for _, c := range contracts {
+		if _, err := parsePublishDate(c.Publish_Date); err != nil {
+			undated++
+		}
+	}
+	if undated == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d contract(s) have a missing or malformed publish date; they are counted in totals but grouped as %q by date", undated, unknownKey)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whatnick/austender_analyser/collector/parse"
+)
+
+func TestFinancialYearLabel(t *testing.T) {
+	fy, err := financialYearLabel(time.Date(2018, time.February, 6, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "2017-18", fy)
+	fy, _ = financialYearLabel(time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, "2018-19", fy)
+}
+
+func TestDateLabelsRefuseZeroTime(t *testing.T) {
+	_, err := financialYearLabel(time.Time{})
+	assert.ErrorIs(t, err, parse.ErrZeroDate)
+	_, err = monthLabel(time.Time{})
+	assert.ErrorIs(t, err, parse.ErrZeroDate)
+}
+
+func TestUnparseablePublishDate(t *testing.T) {
+	for _, s := range []string{"", "31-Feb-2018", "2018-02-06", "1-Jan-0001"} {
+		_, err := parsePublishDate(s)
+		assert.Error(t, err, s)
+	}
+	c := &contract{Publish_Date: "not a date"}
+	assert.Equal(t, unknownKey, fyKey(c))
+	assert.Equal(t, unknownKey, monthKey(c))
+}
+
+func TestUndatedWarning(t *testing.T) {
+	assert.Empty(t, undatedWarning([]*contract{{Publish_Date: "6-Feb-2018"}}))
+	assert.Equal(t,
+		`1 contract(s) have a missing or malformed publish date; they are counted in totals but grouped as "unknown" by date`,
+		undatedWarning([]*contract{{Publish_Date: "6-Feb-2018"}, {Publish_Date: ""}}))
+}
+
+func TestFinancialYearLabelCenturyBoundaries(t *testing.T) {
+	for _, tc := range []struct {
+		date time.Time
+		want string
+	}{
+		{time.Date(2000, time.March, 1, 0, 0, 0, 0, time.UTC), "1999-00"},
+		{time.Date(2000, time.July, 1, 0, 0, 0, 0, time.UTC), "2000-01"},
+		{time.Date(2100, time.June, 30, 0, 0, 0, 0, time.UTC), "2099-00"},
+		{time.Date(2100, time.July, 1, 0, 0, 0, 0, time.UTC), "2100-01"},
+	} {
+		got, err := financialYearLabel(tc.date)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.want, got)
+	}
+	assert.Less(t, "1999-00", "2000-01", "labels sort chronologically as strings")
+	assert.Less(t, "2099-00", "2100-01")
+}
+
+func TestParseFYLabel(t *testing.T) {
+	for label, want := range map[string]int{
+		"2023-24":   2023,
+		"2023-2024": 2023,
+		"1999-00":   1999,
+		"1999-2000": 1999,
+		"2099-00":   2099,
+		"2099-2100": 2099,
+	} {
+		got, err := parseFYLabel(label)
+		assert.NoError(t, err, label)
+		assert.Equal(t, want, got, label)
+	}
+	for _, label := range []string{"", "2023", "2023-25", "2023-2025", "1999-01", "23-24"} {
+		_, err := parseFYLabel(label)
+		assert.Error(t, err, label)
+	}
+}
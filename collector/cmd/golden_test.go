@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// goldenNotices is the fixed dataset behind the output golden files: an
+// original, its amendment and an unrelated contract.
+func goldenNotices() []*contract {
+	return []*contract{
+		sampleContract,
+		{
+			CN_ID: "CN3482539-A1", Amends: "CN3482539", Agency: "Australian National Audit Office",
+			Publish_Date: "1-Jul-2019", Category: "Audit services", Contract_Period: "22-Jan-2018 to 31-Oct-2023",
+			Contract_Value: decimal.RequireFromString("600000.5"), ATM_ID: "2017/1102",
+			Supplier_Name: "KPMG Peat Marwick - ACT", Amount_Includes_GST: true,
+		},
+		{
+			CN_ID: "CN3600002", Agency: "Department of Defence", Publish_Date: "15-Mar-2019",
+			Category: "Management advisory services", Contract_Period: "1-Apr-2019 to 30-Jun-2020",
+			Contract_Value: decimal.RequireFromString("80000.50"), SON_ID: "SON123",
+			Supplier_Name: "Deloitte, \"Consulting\"", Amount_Includes_GST: false,
+		},
+	}
+}
+
+// renderGolden replays the golden dataset through the sink for format.
+func renderGolden(t *testing.T, format string) (string, string) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	var sink OutputSink
+	var err error
+	if format == "bulkfile" {
+		sink, err = newBulkSink(format, &out, &errOut, nil, bulkConfig{})
+	} else {
+		sink, err = newOutputSink(format, &out, &errOut, nil)
+	}
+	assert.NoError(t, err)
+	notices := goldenNotices()
+	for _, c := range notices {
+		sink.OnMatch(c)
+	}
+	sink.OnWarning("1 contract(s) have a missing or malformed publish date")
+	_, totals := aggregateContracts(notices)
+	sink.OnTotal(totals, gstNote(gstExclusive))
+	return out.String(), errOut.String()
+}
+
+func TestOutputGolden(t *testing.T) {
+	for _, format := range []string{"human", "jsonl", "csv", "quiet", "bulkfile"} {
+		out, errOut := renderGolden(t, format)
+		assertGolden(t, "output_"+format+".golden", []byte(out))
+		if format != "jsonl" {
+			assert.Equal(t, "warning: 1 contract(s) have a missing or malformed publish date\n", errOut, format)
+		}
+	}
+}
+
+func TestRunSummaryGolden(t *testing.T) {
+	contracts, totals := aggregateContracts(goldenNotices())
+	res := searchResult{
+		Contracts: contracts, Total: totals.Final, Totals: totals, Observed: 4,
+		Warnings:     []string{"example warning"},
+		Stats:        runStats{Pages: 2, Phases: []phaseTiming{{Name: "fetch", DurationMs: 1200}}},
+		TopSuppliers: topSuppliers(contracts, resultTopSuppliers),
+		DateRange:    publishDateRange(contracts),
+	}
+	req := searchRequest{Company: "KPMG", Agency: "Audit", NormaliseGST: gstExclusive, PageRetries: 2}
+	s := newRunSummary(req, time.Date(2024, time.July, 1, 9, 30, 0, 0, time.UTC), res, nil)
+	s.DurationMs = 1234
+	data, err := json.MarshalIndent(s, "", "  ")
+	assert.NoError(t, err)
+	assertGolden(t, "run_summary.golden", append(data, '\n'))
+}
+
+// TestMoneyFormatGolden pins the accounting package's rendering, which has
+// changed between releases before.
+func TestMoneyFormatGolden(t *testing.T) {
+	var buf bytes.Buffer
+	for _, v := range []string{"0", "0.005", "1", "-1", "999.999", "1000", "80000.5", "-542560", "1234567890.12"} {
+		fmt.Fprintf(&buf, "%s\t%s\n", v, formatMoney(decimal.RequireFromString(v)))
+	}
+	assertGolden(t, "money.golden", buf.Bytes())
+}
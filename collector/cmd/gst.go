@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/shopspring/decimal"
+)
+
+// GST normalisation modes for --normalise-gst.
+const (
+	gstAsPublished = ""
+	gstInclusive   = "inclusive"
+	gstExclusive   = "exclusive"
+)
+
+// AusTender contract notice values are published GST-inclusive.
+const federalAmountIncludesGST = true
+
+var (
+	gstNumerator   = decimal.NewFromInt(10)
+	gstDenominator = decimal.NewFromInt(11)
+)
+
+func validateGSTMode(mode string) error {
+	switch mode {
+	case gstAsPublished, gstInclusive, gstExclusive:
+		return nil
+	}
+	return fmt.Errorf("unsupported GST normalisation %q (want inclusive or exclusive)", mode)
+}
+
+// normaliseGST converts amount to the requested GST basis. A GST-inclusive
+// amount carries 1/11 GST, so ex-GST is 10/11 of it and inc-GST is 11/10 of
+// an ex-GST amount.
+func normaliseGST(amount decimal.Decimal, includesGST bool, mode string) decimal.Decimal {
+	switch {
+	case mode == gstExclusive && includesGST:
+		return amount.Mul(gstNumerator).Div(gstDenominator)
+	case mode == gstInclusive && !includesGST:
+		return amount.Mul(gstDenominator).Div(gstNumerator)
+	}
+	return amount
+}
+
+// gstNote describes the normalisation applied, for display beside totals.
+func gstNote(mode string) string {
+	if mode == gstAsPublished {
+		return ""
+	}
+	return "(amounts normalised to GST-" + mode + ")"
+}
+
+// writeGSTNote prints gstNote for mode under a command's output, if any.
+// Commands printing JSON send it to stderr instead so stdout stays valid.
+func writeGSTNote(w io.Writer, mode string) {
+	if note := gstNote(mode); note != "" {
+		fmt.Fprintln(w, note)
+	}
+}
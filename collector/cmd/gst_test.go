@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormaliseGST(t *testing.T) {
+	inc := decimal.NewFromInt(1100)
+	ex := decimal.NewFromInt(1000)
+
+	assert.True(t, normaliseGST(inc, true, gstExclusive).Equal(ex), "inclusive to exclusive removes 1/11")
+	assert.True(t, normaliseGST(ex, false, gstInclusive).Equal(inc), "exclusive to inclusive adds 1/10")
+	assert.True(t, normaliseGST(inc, true, gstInclusive).Equal(inc), "already inclusive is unchanged")
+	assert.True(t, normaliseGST(ex, false, gstExclusive).Equal(ex), "already exclusive is unchanged")
+	assert.True(t, normaliseGST(inc, true, gstAsPublished).Equal(inc), "no mode keeps published value")
+}
+
+func TestValidateGSTMode(t *testing.T) {
+	assert.NoError(t, validateGSTMode(""))
+	assert.NoError(t, validateGSTMode("exclusive"))
+	assert.Error(t, validateGSTMode("ex"))
+}
+
+func TestSubcommandsNoteGSTNormalisation(t *testing.T) {
+	base := serveFixtures(t)
+	note := gstNote(gstExclusive)
+	run := func(args ...string) (string, string) {
+		args = append(args, "--base-url", base, "--c", "KPMG", "--normalise-gst", gstExclusive)
+		out, errOut, err := runRoot(t, args...)
+		assert.NoError(t, err, args[0])
+		return out, errOut
+	}
+	for _, args := range [][]string{
+		{"breakdown"},
+		{"histogram"},
+		{"concentration", "--d", "Defence"},
+		{"top-suppliers"},
+		{"committed", "--as-of", "2019-01-01"},
+		{"audit", "thresholds"},
+	} {
+		out, _ := run(args...)
+		assert.True(t, strings.HasSuffix(out, note+"\n"), args[0])
+	}
+
+	out, _ := run("report", "agency", "--d", "Defence")
+	assert.True(t, strings.HasSuffix(out, "\n\n_"+note+"_\n"), "report")
+
+	out, errOut := run("histogram", "--json")
+	assert.True(t, json.Valid([]byte(out)), "JSON output stays valid")
+	assert.Contains(t, errOut, note)
+
+	out, _, err := runRoot(t, "breakdown", "--base-url", base, "--c", "KPMG")
+	assert.NoError(t, err)
+	assert.NotContains(t, out, "normalised")
+}
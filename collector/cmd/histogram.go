@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+// defaultHistogramEdges split values at the common dashboard bands:
+// <10k, 10-80k, 80-400k, 400k-1m, 1-10m and >10m.
+var defaultHistogramEdges = []string{"10000", "80000", "400000", "1000000", "10000000"}
+
+// histogramBin is the count and total of contracts in one value band.
+type histogramBin struct {
+	Band string          `json:"band"`
+	Low  decimal.Decimal `json:"low"`
+	// Nil for the open-ended top band
+	High  *decimal.Decimal `json:"high,omitempty"`
+	Count int              `json:"count"`
+	Total decimal.Decimal  `json:"total"`
+}
+
+// bandsFromEdges turns ascending edges into contiguous bands from zero,
+// with an open-ended band above the last edge.
+func bandsFromEdges(edges []string) ([]valueBand, error) {
+	bands := []valueBand{}
+	low := decimal.Zero
+	for _, e := range edges {
+		edge, err := decimal.NewFromString(strings.TrimSpace(e))
+		if err != nil {
+			return nil, fmt.Errorf("invalid band edge %q", e)
+		}
+		if !edge.GreaterThan(low) {
+			return nil, fmt.Errorf("band edges must be positive and ascending, got %s after %s", edge, low)
+		}
+		bands = append(bands, valueBand{Low: low, High: edge})
+		low = edge
+	}
+	return append(bands, valueBand{Low: low}), nil
+}
+
+// valueHistogram counts and sums contracts per band. Values below the
+// first band (none, for scraped notices) are ignored.
+func valueHistogram(contracts []*contract, bands []valueBand) []histogramBin {
+	bins := make([]histogramBin, len(bands))
+	for i, b := range bands {
+		bins[i] = histogramBin{Band: b.label(), Low: b.Low}
+		if !b.High.IsZero() {
+			high := b.High
+			bins[i].High = &high
+		}
+	}
+	for _, c := range contracts {
+		for i, b := range bands {
+			if b.contains(c.Contract_Value) {
+				bins[i].Count++
+				bins[i].Total = bins[i].Total.Add(c.Contract_Value)
+				break
+			}
+		}
+	}
+	return bins
+}
+
+func writeHistogram(w io.Writer, bins []histogramBin, colour bool) error {
+	t := newTextTable(tableColumn{Heading: "Band"}, tableColumn{Heading: "Count", Right: true}, tableColumn{Heading: "Total", Right: true})
+	for _, b := range bins {
+		t.addRow(plainCells(b.Band, fmt.Sprint(b.Count), formatMoney(b.Total))...)
+	}
+	return t.render(w, colour)
+}
+
+var histogramCmd = &cobra.Command{
+	Use:   "histogram",
+	Short: "Count and total matching contracts per value band",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		edges, _ := cmd.Flags().GetStringSlice("edges")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		bands, err := bandsFromEdges(edges)
+		if err != nil {
+			return err
+		}
+		cfg := resolveConfig(os.Environ())
+		req := requestFromFlags(cmd, cfg)
+		res, err := quietSearch(cmd, req)
+		if err != nil {
+			return err
+		}
+		bins := valueHistogram(res.Contracts, bands)
+		if asJSON {
+			writeGSTNote(cmd.ErrOrStderr(), req.NormaliseGST)
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			enc.SetEscapeHTML(false)
+			return enc.Encode(bins)
+		}
+		if err := writeHistogram(cmd.OutOrStdout(), bins, colourEnabled(cmd.OutOrStdout(), cfg)); err != nil {
+			return err
+		}
+		writeGSTNote(cmd.OutOrStdout(), req.NormaliseGST)
+		return nil
+	},
+}
+
+func init() {
+	histogramCmd.Flags().StringSlice("edges", defaultHistogramEdges, "Ascending band edges in dollars")
+	histogramCmd.Flags().Bool("json", false, "Print bands as JSON")
+	rootCmd.AddCommand(histogramCmd)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBandsFromEdges(t *testing.T) {
+	bands, err := bandsFromEdges(defaultHistogramEdges)
+	assert.NoError(t, err)
+	labels := []string{}
+	for _, b := range bands {
+		labels = append(labels, b.label())
+	}
+	assert.Equal(t, []string{"<$10k", "$10k-$80k", "$80k-$400k", "$400k-$1m", "$1m-$10m", ">$10m"}, labels)
+
+	_, err = bandsFromEdges([]string{"80000", "10000"})
+	assert.Error(t, err)
+	_, err = bandsFromEdges([]string{"10k"})
+	assert.Error(t, err)
+}
+
+func TestValueHistogram(t *testing.T) {
+	contracts := contractsAt(3, 5000, "1-Aug-2022")
+	contracts = append(contracts, contractsAt(2, 80000, "1-Aug-2022")...)
+	contracts = append(contracts, contractsAt(1, 25000000, "1-Aug-2022")...)
+	bands, _ := bandsFromEdges(defaultHistogramEdges)
+
+	bins := valueHistogram(contracts, bands)
+	counts := []int{}
+	for _, b := range bins {
+		counts = append(counts, b.Count)
+	}
+	assert.Equal(t, []int{3, 0, 2, 0, 0, 1}, counts, "a value on an edge falls in the band above")
+	assert.True(t, bins[0].Total.Equal(decimal.NewFromInt(15000)))
+	assert.True(t, bins[2].Total.Equal(decimal.NewFromInt(160000)))
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeHistogram(&buf, bins, false))
+	assert.Contains(t, buf.String(), ">$10m")
+}
+
+func TestHistogramCommand(t *testing.T) {
+	base := serveFixtures(t)
+	out, _, err := runRoot(t, "histogram", "--base-url", base, "--c", "KPMG", "--edges", "100000,500000", "--json", "--no-progress")
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"band": "<$100k"`)
+	assert.Contains(t, out, `"band": "$100k-$500k"`)
+	assert.Contains(t, out, `"band": ">$500k"`)
+	assert.NotContains(t, out, `"high": "0"`, "the top band is open-ended")
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// nameFolds maps punctuation that varies between otherwise identical names
+// (smart quotes, dashes) to a plain ASCII form.
+var nameFolds = strings.NewReplacer(
+	"‘", "'", "’", "'", "“", `"`, "”", `"`,
+	"–", "-", "—", "-",
+)
+
+// normalizeName folds an agency or supplier name for comparison: NFKD with
+// diacritics stripped, lower-cased, smart punctuation made plain and runs of
+// whitespace (including non-breaking spaces) collapsed to one space.
+func normalizeName(s string) string {
+	t := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	folded = nameFolds.Replace(strings.ToLower(folded))
+	return strings.Join(strings.FieldsFunc(folded, unicode.IsSpace), " ")
+}
+
+// nameContains reports whether name contains filter once both are normalised.
+func nameContains(name, filter string) bool {
+	return strings.Contains(normalizeName(name), normalizeName(filter))
+}
+
+// Ways a supplier name can match the company filter, as counted in
+// searchResult.CompanyMatches.
+const (
+	companyMatchWord      = "word"
+	companyMatchSubstring = "substring"
+)
+
+// nameTokens splits a normalised name into its words, dropping punctuation
+// so "Ernst & Young (EY)" yields "ernst", "young" and "ey".
+func nameTokens(s string) []string {
+	return strings.FieldsFunc(normalizeName(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// companyMatch reports whether a supplier AusTender returned for the company
+// filter should be kept, and how it matched. Filters shorter than wordUnder
+// characters must match whole words, so "EY" keeps "EY Australia" but not
+// "Sydney Water". Longer filters, and all filters when wordUnder is zero,
+// are left to AusTender's own substring search and always keep the
+// supplier. An empty filter keeps everything with an empty mode.
+func companyMatch(supplier, filter string, wordUnder int) (bool, string) {
+	if filter == "" {
+		return true, ""
+	}
+	if utf8.RuneCountInString(normalizeName(filter)) >= wordUnder || len(nameTokens(filter)) == 0 {
+		return true, companyMatchSubstring
+	}
+	return containsWords(supplier, filter), companyMatchWord
+}
+
+// containsWords reports whether the words of filter appear consecutively
+// among the words of name. A filter with no words never matches.
+func containsWords(name, filter string) bool {
+	want, have := nameTokens(filter), nameTokens(name)
+	if len(want) == 0 {
+		return false
+	}
+	for i := 0; i+len(want) <= len(have); i++ {
+		if slices.Equal(have[i:i+len(want)], want) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeName(t *testing.T) {
+	cases := map[string]string{
+		"Université de Sydney":     "universite de sydney",
+		"NOUS  GROUP PTY LTD":      "nous group pty ltd",
+		"O’Connor – Partners":      "o'connor - partners",
+		"“Quoted” Pty":             `"quoted" pty`,
+		"ｆｕｌｌｗｉｄｔｈ":                "fullwidth",
+		"  Department of Defence ": "department of defence",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, normalizeName(in), in)
+	}
+}
+
+func TestNameContains(t *testing.T) {
+	assert.True(t, nameContains("Universite de Sydney", "Université"))
+	assert.True(t, nameContains("NOUS GROUP PTY LTD", "Nous Group"))
+	assert.True(t, nameContains("O'Connor Partners", "O’Connor"))
+	assert.True(t, nameContains("Anything", ""))
+	assert.False(t, nameContains("Department of Defence", "Treasury"))
+}
+
+func TestCompanyMatch(t *testing.T) {
+	cases := []struct {
+		supplier, filter string
+		ok               bool
+		mode             string
+	}{
+		{"EY Australia", "EY", true, companyMatchWord},
+		{"Ernst & Young (EY)", "ey", true, companyMatchWord},
+		{"Sydney Harbour Federation Trust", "EY", false, companyMatchWord},
+		{"PwC Consulting", "PwC", true, companyMatchWord},
+		{"PricewaterhouseCoopers", "PwC", false, companyMatchWord},
+		{"KPMG Peat Marwick - ACT", "KPMG", true, companyMatchWord},
+		{"Boston Consulting Group", "BC G", false, companyMatchWord},
+		{"Deloitte Touche Tohmatsu", "Deloitte", true, companyMatchSubstring},
+		{"Ernst & Young", "Ernst Young", true, companyMatchSubstring},
+		{"Anything", "", true, ""},
+	}
+	for _, c := range cases {
+		ok, mode := companyMatch(c.supplier, c.filter, 5)
+		assert.Equal(t, c.ok, ok, "%s / %s", c.supplier, c.filter)
+		assert.Equal(t, c.mode, mode, "%s / %s", c.supplier, c.filter)
+	}
+
+	ok, mode := companyMatch("Sydney Harbour Federation Trust", "EY", 0)
+	assert.True(t, ok, "zero threshold keeps substring matching")
+	assert.Equal(t, companyMatchSubstring, mode)
+}
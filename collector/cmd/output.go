@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// OutputSink receives everything a search run reports. Implementations
+// decide how (and whether) to render each event.
+type OutputSink interface {
+	// OnProgress reports pages scraped so far out of pages requested.
+	OnProgress(done, total int)
+	OnMatch(c *contract)
+	OnWarning(msg string)
+	// OnTotal reports the final totals with an optional note on how
+	// amounts were adjusted. It is called once, at the end of the run.
+	OnTotal(totals searchTotals, note string)
+}
+
+// newOutputSink returns the sink for an --output format. Results go to w,
+// diagnostics to errW and progress to progress, which is nil when disabled.
+func newOutputSink(format string, w, errW io.Writer, progress *progressPrinter) (OutputSink, error) {
+	switch format {
+	case "", "human":
+		return &humanSink{w: w, errW: errW, progress: progress}, nil
+	case "jsonl":
+		return &jsonLinesSink{enc: json.NewEncoder(w), progress: progress}, nil
+	case "csv":
+		return &csvSink{w: csv.NewWriter(w), errW: errW, progress: progress}, nil
+	case "quiet":
+		return &quietSink{w: w, errW: errW}, nil
+	}
+	return nil, fmt.Errorf("unsupported output %q (want human, jsonl, csv, quiet, bulkfile or opensearch)", format)
+}
+
+// humanSink prints match lines and the total for a person at a terminal.
+type humanSink struct {
+	w, errW  io.Writer
+	progress *progressPrinter
+}
+
+func (s *humanSink) OnProgress(done, total int) {
+	s.progress.update(done, total)
+}
+
+func (s *humanSink) OnMatch(c *contract) {
+	update := ""
+	if c.isUpdate() {
+		update = " [amendment]"
+	}
+	fmt.Fprintf(s.w, "%s (%s) %s -> %s: %s%s\n", c.CN_ID, c.Publish_Date, c.Agency, c.Supplier_Name, formatMoney(c.Contract_Value), update)
+}
+
+func (s *humanSink) OnWarning(msg string) {
+	fmt.Fprintln(s.errW, "warning: "+msg)
+}
+
+func (s *humanSink) OnTotal(totals searchTotals, note string) {
+	s.progress.finish()
+	fmt.Fprintln(s.w, "Total Contract:"+formatMoney(totals.Final))
+	if !totals.Amended.IsZero() {
+		sign := "+"
+		if totals.Amended.IsNegative() {
+			sign = ""
+		}
+		fmt.Fprintf(s.w, "Originally awarded %s, %s%s through amendments\n", formatMoney(totals.Original), sign, formatMoney(totals.Amended))
+	}
+	if note != "" {
+		fmt.Fprintln(s.w, note)
+	}
+}
+
+// jsonLinesSink writes one JSON object per match, warning and total.
+// Progress goes to the progress printer so the stream holds only records.
+type jsonLinesSink struct {
+	enc      *json.Encoder
+	progress *progressPrinter
+}
+
+type jsonLinesEvent struct {
+	Event    string        `json:"event"`
+	Contract *contract     `json:"contract,omitempty"`
+	Message  string        `json:"message,omitempty"`
+	Totals   *searchTotals `json:"totals,omitempty"`
+	Note     string        `json:"note,omitempty"`
+}
+
+func (s *jsonLinesSink) OnProgress(done, total int) {
+	s.progress.update(done, total)
+}
+
+func (s *jsonLinesSink) OnMatch(c *contract) {
+	s.enc.Encode(jsonLinesEvent{Event: "match", Contract: c})
+}
+
+func (s *jsonLinesSink) OnWarning(msg string) {
+	s.enc.Encode(jsonLinesEvent{Event: "warning", Message: msg})
+}
+
+func (s *jsonLinesSink) OnTotal(totals searchTotals, note string) {
+	s.progress.finish()
+	s.enc.Encode(jsonLinesEvent{Event: "total", Totals: &totals, Note: note})
+}
+
+var csvHeader = []string{
+	"cn_id", "amends", "agency", "publish_date", "category", "contract_period",
+	"contract_value", "atm_id", "son_id", "supplier_name", "amount_includes_gst",
+	"is_update",
+}
+
+// csvSink writes one row per matched contract; warnings go to errW.
+type csvSink struct {
+	w           *csv.Writer
+	errW        io.Writer
+	progress    *progressPrinter
+	wroteHeader bool
+}
+
+func (s *csvSink) OnProgress(done, total int) {
+	s.progress.update(done, total)
+}
+
+func (s *csvSink) OnMatch(c *contract) {
+	if !s.wroteHeader {
+		s.w.Write(csvHeader)
+		s.wroteHeader = true
+	}
+	s.w.Write(csvRow(c))
+	s.w.Flush()
+}
+
+// csvRow formats c for spreadsheets: amounts with two fixed decimals and
+// publish dates as YYYY-MM-DD. Unparseable dates are kept as published.
+func csvRow(c *contract) []string {
+	published := c.Publish_Date
+	if t, err := parsePublishDate(c.Publish_Date); err == nil {
+		published = t.Format(time.DateOnly)
+	}
+	return []string{
+		c.CN_ID, c.Amends, c.Agency, published, c.Category, c.Contract_Period,
+		c.Contract_Value.StringFixed(2), c.ATM_ID, c.SON_ID, c.Supplier_Name,
+		fmt.Sprint(c.Amount_Includes_GST), fmt.Sprint(c.isUpdate()),
+	}
+}
+
+func (s *csvSink) OnWarning(msg string) {
+	fmt.Fprintln(s.errW, "warning: "+msg)
+}
+
+func (s *csvSink) OnTotal(totals searchTotals, note string) {
+	s.progress.finish()
+	if !s.wroteHeader {
+		s.w.Write(csvHeader)
+		s.wroteHeader = true
+	}
+	s.w.Flush()
+}
+
+// quietSink prints only the total, for scripts.
+type quietSink struct {
+	w, errW io.Writer
+}
+
+func (s *quietSink) OnProgress(done, total int) {}
+
+func (s *quietSink) OnMatch(c *contract) {}
+
+func (s *quietSink) OnWarning(msg string) {
+	fmt.Fprintln(s.errW, "warning: "+msg)
+}
+
+func (s *quietSink) OnTotal(totals searchTotals, note string) {
+	fmt.Fprintln(s.w, totals.Final.StringFixed(2))
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var sampleContract = &contract{
+	CN_ID:               "CN3482539",
+	Agency:              "Australian National Audit Office",
+	Publish_Date:        "6-Feb-2018",
+	Category:            "Audit services",
+	Contract_Period:     "22-Jan-2018 to 31-Oct-2023",
+	Contract_Value:      decimal.RequireFromString("542560"),
+	ATM_ID:              "2017/1102",
+	Supplier_Name:       "KPMG Peat Marwick - ACT",
+	Amount_Includes_GST: true,
+}
+
+func TestNewOutputSinkRejectsUnknownFormat(t *testing.T) {
+	_, err := newOutputSink("xml", nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestHumanSink(t *testing.T) {
+	var out, errOut bytes.Buffer
+	sink, _ := newOutputSink("human", &out, &errOut, nil)
+	sink.OnMatch(sampleContract)
+	sink.OnWarning("page failed")
+	sink.OnTotal(searchTotals{Original: decimal.RequireFromString("542560"), Final: decimal.RequireFromString("542560")}, "")
+
+	assert.Equal(t, "CN3482539 (6-Feb-2018) Australian National Audit Office -> KPMG Peat Marwick - ACT: $542,560.00\nTotal Contract:$542,560.00\n", out.String())
+	assert.Equal(t, "warning: page failed\n", errOut.String())
+}
+
+func TestJSONLinesSink(t *testing.T) {
+	var out bytes.Buffer
+	sink, _ := newOutputSink("jsonl", &out, nil, nil)
+	sink.OnMatch(sampleContract)
+	sink.OnTotal(searchTotals{Original: decimal.RequireFromString("542560"), Final: decimal.RequireFromString("542560")}, "")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	var match jsonLinesEvent
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &match))
+	assert.Equal(t, "match", match.Event)
+	assert.Equal(t, "CN3482539", match.Contract.CN_ID)
+	var total jsonLinesEvent
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &total))
+	assert.Equal(t, "total", total.Event)
+	assert.True(t, total.Totals.Final.Equal(decimal.RequireFromString("542560")))
+}
+
+func TestJSONLinesProgressStaysOffTheStream(t *testing.T) {
+	var out, errOut bytes.Buffer
+	sink, _ := newOutputSink("jsonl", &out, &errOut, newProgressPrinter(&errOut))
+	sink.OnProgress(1, 1)
+	sink.OnMatch(sampleContract)
+	sink.OnTotal(searchTotals{}, "")
+
+	assert.NotContains(t, out.String(), "progress")
+	assert.Len(t, strings.Split(strings.TrimSpace(out.String()), "\n"), 2, "only match and total records")
+	assert.Equal(t, "Scraped 1/1 pages (100%)\n", errOut.String())
+}
+
+func TestCSVSink(t *testing.T) {
+	var out bytes.Buffer
+	sink, _ := newOutputSink("csv", &out, nil, nil)
+	sink.OnMatch(sampleContract)
+	sink.OnTotal(searchTotals{Original: decimal.RequireFromString("542560"), Final: decimal.RequireFromString("542560")}, "")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, strings.Join(csvHeader, ","), lines[0])
+	assert.Equal(t, "CN3482539,,Australian National Audit Office,2018-02-06,Audit services,22-Jan-2018 to 31-Oct-2023,542560.00,2017/1102,,KPMG Peat Marwick - ACT,true,false", lines[1])
+}
+
+func TestCSVSinkQuotesAndFormats(t *testing.T) {
+	var out bytes.Buffer
+	sink, _ := newOutputSink("csv", &out, nil, nil)
+	sink.OnMatch(&contract{
+		CN_ID:          "CN1-A1",
+		Amends:         "CN1",
+		Agency:         "Department of Defence",
+		Publish_Date:   "15-Mar-2019",
+		Category:       "Advisory, audit\nand assurance",
+		Contract_Value: decimal.RequireFromString("80000.5"),
+		Supplier_Name:  `Deloitte "Consulting"`,
+	})
+	sink.OnMatch(&contract{CN_ID: "CN2", Publish_Date: "sometime", Contract_Value: decimal.NewFromInt(7)})
+	sink.OnTotal(searchTotals{}, "")
+
+	raw := out.String()
+	assert.Contains(t, raw, `"Advisory, audit`+"\n"+`and assurance"`)
+	records, err := csv.NewReader(strings.NewReader(raw)).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, []string{
+		"CN1-A1", "CN1", "Department of Defence", "2019-03-15", "Advisory, audit\nand assurance", "",
+		"80000.50", "", "", `Deloitte "Consulting"`, "false", "true",
+	}, records[1])
+	assert.Equal(t, "sometime", records[2][3])
+	assert.Equal(t, "7.00", records[2][6])
+}
+
+func TestQuietSink(t *testing.T) {
+	var out bytes.Buffer
+	sink, _ := newOutputSink("quiet", &out, nil, nil)
+	sink.OnMatch(sampleContract)
+	sink.OnTotal(searchTotals{Original: decimal.RequireFromString("542560"), Final: decimal.RequireFromString("542560")}, "")
+	assert.Equal(t, "542560.00\n", out.String())
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// unmappedPortfolio labels agencies missing from the portfolio map.
+const unmappedPortfolio = "(unmapped)"
+
+// defaultPortfolioCSV maps federal agencies to their portfolio under the
+// current Administrative Arrangements Order. Agencies that have since been
+// renamed or abolished are not listed and show as unmapped.
+//
+//go:embed portfolios.csv
+var defaultPortfolioCSV string
+
+// portfolioMap maps normalised agency names to portfolio names.
+type portfolioMap map[string]string
+
+// portfolios is the map used by searches and breakdowns; --portfolio-map
+// replaces it.
+var portfolios = mustParsePortfolioCSV(defaultPortfolioCSV)
+
+// parsePortfolioCSV reads "agency,portfolio" rows after a header row.
+func parsePortfolioCSV(r io.Reader) (portfolioMap, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || !strings.EqualFold(rows[0][0], "agency") {
+		return nil, fmt.Errorf("portfolio map must start with an agency,portfolio header")
+	}
+	m := portfolioMap{}
+	for _, row := range rows[1:] {
+		if len(row) != 2 || strings.TrimSpace(row[0]) == "" || strings.TrimSpace(row[1]) == "" {
+			return nil, fmt.Errorf("invalid portfolio map row %q", strings.Join(row, ","))
+		}
+		m[normalizeName(row[0])] = strings.TrimSpace(row[1])
+	}
+	return m, nil
+}
+
+func mustParsePortfolioCSV(s string) portfolioMap {
+	m, err := parsePortfolioCSV(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func loadPortfolioMap(path string) (portfolioMap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := parsePortfolioCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// portfolioOf returns the portfolio of agency, or unmappedPortfolio.
+func (m portfolioMap) portfolioOf(agency string) string {
+	if p, ok := m[normalizeName(agency)]; ok {
+		return p
+	}
+	return unmappedPortfolio
+}
+
+// agencies expands portfolio to its member agencies (normalised, sorted).
+func (m portfolioMap) agencies(portfolio string) []string {
+	members := []string{}
+	for agency, p := range m {
+		if normalizeName(p) == normalizeName(portfolio) {
+			members = append(members, agency)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// names returns the distinct portfolio names, sorted.
+func (m portfolioMap) names() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, p := range m {
+		if !seen[p] {
+			seen[p] = true
+			names = append(names, p)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func portfolioKey(c *contract) string {
+	return portfolios.portfolioOf(c.Agency)
+}
+
+// unmappedShare reports how many contracts, and how much value, fall under
+// agencies missing from the portfolio map, or "" when all are mapped.
+func unmappedShare(contracts []*contract) string {
+	for _, g := range groupContracts(contracts, portfolioKey) {
+		if g.Key == unmappedPortfolio {
+			return fmt.Sprintf("%d of %d contract(s) (%s) are from agencies not in the portfolio map", g.Count, len(contracts), formatMoney(g.Total))
+		}
+	}
+	return ""
+}
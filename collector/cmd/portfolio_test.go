@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultPortfolioMap(t *testing.T) {
+	assert.Equal(t, "Social Services", portfolios.portfolioOf("Services Australia"))
+	assert.Equal(t, "Defence", portfolios.portfolioOf("DEPARTMENT OF DEFENCE"))
+	assert.Equal(t, unmappedPortfolio, portfolios.portfolioOf("Department of Magic"))
+	assert.Contains(t, portfolios.agencies("defence"), "department of veterans' affairs")
+	assert.Empty(t, portfolios.agencies("Magic"))
+}
+
+func TestParsePortfolioCSV(t *testing.T) {
+	m, err := parsePortfolioCSV(strings.NewReader("agency,portfolio\n\"Department of Magic\",Wizardry\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Wizardry", m.portfolioOf("Department of Magic"))
+
+	_, err = parsePortfolioCSV(strings.NewReader("Department of Magic,Wizardry\n"))
+	assert.Error(t, err, "header is required")
+	_, err = parsePortfolioCSV(strings.NewReader("agency,portfolio\nDepartment of Magic,\n"))
+	assert.Error(t, err)
+}
+
+func TestPortfolioRollup(t *testing.T) {
+	contracts := []*contract{
+		{Agency: "Department of Defence", Contract_Value: decimal.NewFromInt(100)},
+		{Agency: "Department of Veterans' Affairs", Contract_Value: decimal.NewFromInt(50)},
+		{Agency: "Services Australia", Contract_Value: decimal.NewFromInt(20)},
+		{Agency: "Department of Magic", Contract_Value: decimal.NewFromInt(5)},
+	}
+	groups := groupContracts(contracts, portfolioKey)
+	assert.Equal(t, []string{unmappedPortfolio, "Defence", "Social Services"}, []string{groups[0].Key, groups[1].Key, groups[2].Key})
+	assert.True(t, groups[1].Total.Equal(decimal.NewFromInt(150)))
+	assert.Equal(t, "1 of 4 contract(s) ($5.00) are from agencies not in the portfolio map", unmappedShare(contracts))
+}
+
+func TestScrapeAncapPortfolioFilter(t *testing.T) {
+	base := serveFixtures(t)
+
+	res, err := scrapeAncap(searchRequest{BaseURL: base, Company: "KPMG", Portfolio: "Defence"}, &recordingSink{})
+	assert.NoError(t, err)
+	assert.True(t, res.Total.Equal(decimal.RequireFromString("200000.50")))
+	for _, c := range res.Contracts {
+		assert.Equal(t, "Defence", c.Portfolio)
+	}
+
+	_, err = scrapeAncap(searchRequest{BaseURL: base, Portfolio: "Magic"}, &recordingSink{})
+	assert.EqualError(t, err, `unknown portfolio "Magic"`)
+}
+
+func TestBreakdownByPortfolioWithCustomMap(t *testing.T) {
+	t.Cleanup(func() { portfolios = mustParsePortfolioCSV(defaultPortfolioCSV) })
+	path := filepath.Join(t.TempDir(), "map.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("agency,portfolio\nDepartment of Defence,Defence\n"), 0o644))
+
+	base := serveFixtures(t)
+	out, errOut, err := runRoot(t, "breakdown", "--base-url", base, "--c", "KPMG", "--by", "portfolio", "--portfolio-map", path, "--no-progress")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "Defence")
+	assert.Contains(t, out, unmappedPortfolio)
+	assert.Contains(t, errOut, "note: 1 of 3 contract(s) ($542,560.00) are from agencies not in the portfolio map")
+}
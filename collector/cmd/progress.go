@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// progressPrinter renders scrape progress on its own stream, normally
+// stderr, so it never mixes with results on stdout. On a terminal it
+// redraws one line in place; otherwise it prints occasional plain lines.
+type progressPrinter struct {
+	w   io.Writer
+	tty bool
+	// Latest counts seen, and the done count of the last plain line
+	done, total, printed int
+}
+
+// nonTTYProgressEvery is how many pages pass between plain progress lines.
+const nonTTYProgressEvery = 10
+
+func newProgressPrinter(w io.Writer) *progressPrinter {
+	return &progressPrinter{w: w, tty: isTerminal(w)}
+}
+
+// update reports progress. Pages are discovered as the crawl goes, so done
+// can catch up with total before the run ends; only finish marks the end.
+func (p *progressPrinter) update(done, total int) {
+	if p == nil {
+		return
+	}
+	p.done, p.total = done, total
+	if p.tty {
+		fmt.Fprintf(p.w, "\rScraped %d/%d pages", done, total)
+		return
+	}
+	if done%nonTTYProgressEvery == 0 {
+		p.line()
+	}
+}
+
+// finish ends the progress output once the run is over: the newline after
+// the redrawn line on a terminal, otherwise a last plain line.
+func (p *progressPrinter) finish() {
+	if p == nil || p.total == 0 {
+		return
+	}
+	if p.tty {
+		fmt.Fprintln(p.w)
+	} else if p.printed != p.done {
+		p.line()
+	}
+	p.total = 0
+}
+
+func (p *progressPrinter) line() {
+	fmt.Fprintf(p.w, "Scraped %d/%d pages (%d%%)\n", p.done, p.total, p.done*100/p.total)
+	p.printed = p.done
+}
+
+// isTerminal reports whether w is a character device such as a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
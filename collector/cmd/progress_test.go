@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressPrinterNonTTY(t *testing.T) {
+	var buf bytes.Buffer
+	p := newProgressPrinter(&buf)
+	assert.False(t, p.tty, "a buffer is not a terminal")
+	for done := 1; done <= 12; done++ {
+		p.update(done, 12)
+	}
+	p.finish()
+	assert.Equal(t, "Scraped 10/12 pages (83%)\nScraped 12/12 pages (100%)\n", buf.String())
+	assert.NotContains(t, buf.String(), "\r")
+}
+
+func TestProgressPrinterTTY(t *testing.T) {
+	var buf bytes.Buffer
+	p := &progressPrinter{w: &buf, tty: true}
+	p.update(1, 1)
+	p.update(1, 2)
+	p.update(2, 2)
+	p.finish()
+	p.finish()
+	assert.Equal(t, "\rScraped 1/1 pages\rScraped 1/2 pages\rScraped 2/2 pages\n", buf.String(), "one newline, at the end")
+}
+
+func TestNilProgressPrinterIsDisabled(t *testing.T) {
+	var p *progressPrinter
+	assert.NotPanics(t, func() { p.update(1, 1); p.finish() })
+}
+
+func TestProgressPrinterWaitsForFinish(t *testing.T) {
+	var buf bytes.Buffer
+	p := newProgressPrinter(&buf)
+	// Page 2 is only discovered after page 1 is scraped
+	p.update(1, 1)
+	p.update(2, 2)
+	assert.Empty(t, buf.String(), "catching up with the known pages is not completion")
+	p.finish()
+	p.finish()
+	assert.Equal(t, "Scraped 2/2 pages (100%)\n", buf.String())
+}
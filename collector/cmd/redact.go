@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// supplierRedactor replaces supplier names with stable per-run pseudonyms
+// ("Supplier A", "Supplier B", ...) so outputs can be shared externally.
+// Names that normalise alike share a pseudonym. A nil redactor leaves
+// everything unchanged.
+type supplierRedactor struct {
+	mu      sync.Mutex
+	byName  map[string]string
+	aliases map[string][]string
+	raw     []string
+}
+
+func newSupplierRedactor() *supplierRedactor {
+	return &supplierRedactor{byName: map[string]string{}, aliases: map[string][]string{}}
+}
+
+// redactor is the active redactor for this run, set from --redact-suppliers.
+var redactor *supplierRedactor
+
+// pseudonym returns "Supplier A".."Supplier Z", "Supplier AA" and so on for
+// the n-th (zero-based) supplier.
+func pseudonym(n int) string {
+	label := ""
+	for n++; n > 0; n = (n - 1) / 26 {
+		label = string(rune('A'+(n-1)%26)) + label
+	}
+	return "Supplier " + label
+}
+
+// name returns the pseudonym for supplier, assigning one on first sight.
+func (r *supplierRedactor) name(supplier string) string {
+	if r == nil || supplier == "" {
+		return supplier
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := normalizeName(supplier)
+	p, ok := r.byName[key]
+	if !ok {
+		p = pseudonym(len(r.byName))
+		r.byName[key] = p
+	}
+	if !containsString(r.aliases[p], supplier) {
+		r.aliases[p] = append(r.aliases[p], supplier)
+		r.raw = append(r.raw, supplier)
+		// Longest first so a name is replaced before any name it contains
+		sort.SliceStable(r.raw, func(i, j int) bool { return len(r.raw[i]) > len(r.raw[j]) })
+	}
+	return p
+}
+
+// text is a best-effort pass replacing known supplier names embedded in
+// free text, ignoring case, including URL-encoded forms such as those in
+// page URLs.
+func (r *supplierRedactor) text(s string) string {
+	if r == nil {
+		return s
+	}
+	r.mu.Lock()
+	raw := append([]string{}, r.raw...)
+	r.mu.Unlock()
+	for _, name := range raw {
+		p := r.name(name)
+		s = replaceFold(s, name, p)
+		s = replaceFold(s, url.QueryEscape(name), url.QueryEscape(p))
+	}
+	return s
+}
+
+// replaceFold replaces every case-insensitive occurrence of old in s.
+func replaceFold(s, old, new string) string {
+	return regexp.MustCompile("(?i)"+regexp.QuoteMeta(old)).ReplaceAllLiteralString(s, new)
+}
+
+// contract returns a redacted copy of c.
+func (r *supplierRedactor) contract(c *contract) *contract {
+	if r == nil {
+		return c
+	}
+	redacted := *c
+	redacted.Supplier_Name = r.name(c.Supplier_Name)
+	redacted.Category = r.text(c.Category)
+	return &redacted
+}
+
+func (r *supplierRedactor) contracts(cs []*contract) []*contract {
+	if r == nil {
+		return cs
+	}
+	out := make([]*contract, len(cs))
+	for i, c := range cs {
+		out[i] = r.contract(c)
+	}
+	return out
+}
+
+// result redacts everything in res that can carry a supplier name.
+func (r *supplierRedactor) result(res searchResult) searchResult {
+	if r == nil {
+		return res
+	}
+	res.Contracts = r.contracts(res.Contracts)
+	if res.History != nil {
+		// A copy, so the caller's unredacted result is left intact
+		history := make(map[string][]*contract, len(res.History))
+		for id, h := range res.History {
+			history[id] = r.contracts(h)
+		}
+		res.History = history
+	}
+	top := make([]supplierTotal, len(res.TopSuppliers))
+	for i, st := range res.TopSuppliers {
+		st.Name = r.name(st.Name)
+		top[i] = st
+	}
+	res.TopSuppliers = top
+	warnings := make([]string, len(res.Warnings))
+	for i, w := range res.Warnings {
+		warnings[i] = r.text(w)
+	}
+	res.Warnings = warnings
+	return res
+}
+
+// writeKey writes the pseudonym to real name mapping as CSV, for internal
+// use only. The file is readable by its owner alone, as it undoes the
+// redaction.
+func (r *supplierRedactor) writeKey(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pseudonyms := make([]string, 0, len(r.aliases))
+	for p := range r.aliases {
+		pseudonyms = append(pseudonyms, p)
+	}
+	sort.Slice(pseudonyms, func(i, j int) bool {
+		if len(pseudonyms[i]) != len(pseudonyms[j]) {
+			return len(pseudonyms[i]) < len(pseudonyms[j])
+		}
+		return pseudonyms[i] < pseudonyms[j]
+	})
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	// OpenFile keeps the mode of an existing file
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"pseudonym", "supplier"})
+	for _, p := range pseudonyms {
+		for _, name := range r.aliases[p] {
+			w.Write([]string{p, name})
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// redactingSink redacts supplier names before passing events to next.
+type redactingSink struct {
+	next OutputSink
+	r    *supplierRedactor
+}
+
+func (s *redactingSink) OnProgress(done, total int) {
+	s.next.OnProgress(done, total)
+}
+
+func (s *redactingSink) OnMatch(c *contract) {
+	s.next.OnMatch(s.r.contract(c))
+}
+
+func (s *redactingSink) OnWarning(msg string) {
+	s.next.OnWarning(s.r.text(msg))
+}
+
+func (s *redactingSink) OnTotal(totals searchTotals, note string) {
+	s.next.OnTotal(totals, note)
+}
+
+// quietSearch runs req for a subcommand that renders its own output,
+// reporting only warnings, and redacts the result when enabled.
+func quietSearch(cmd *cobra.Command, req searchRequest) (searchResult, error) {
+	res, err := unredactedSearch(cmd, req)
+	return redactor.result(res), err
+}
+
+// unredactedSearch is quietSearch for commands that must work on real
+// supplier names, such as grouping name variants, and redact only what they
+// print. It writes --summary-file for the search.
+func unredactedSearch(cmd *cobra.Command, req searchRequest) (searchResult, error) {
+	started := time.Now()
+	res, err := silentSearch(cmd, req)
+	if path, _ := cmd.Flags().GetString("summary-file"); path != "" {
+		if werr := writeRunSummary(path, redactedRunSummary(req, started, res, err)); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return res, err
+}
+
+// silentSearch runs req with only warnings reported, redacted, on stderr.
+// The result is unredacted.
+func silentSearch(cmd *cobra.Command, req searchRequest) (searchResult, error) {
+	var sink OutputSink = &quietSink{w: io.Discard, errW: cmd.ErrOrStderr()}
+	if redactor != nil {
+		// The company filter is a supplier name too
+		redactor.name(req.Company)
+		sink = &redactingSink{next: sink, r: redactor}
+	}
+	return scrapeAncap(req, sink)
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPseudonym(t *testing.T) {
+	assert.Equal(t, "Supplier A", pseudonym(0))
+	assert.Equal(t, "Supplier Z", pseudonym(25))
+	assert.Equal(t, "Supplier AA", pseudonym(26))
+	assert.Equal(t, "Supplier AB", pseudonym(27))
+}
+
+func TestSupplierRedactor(t *testing.T) {
+	r := newSupplierRedactor()
+	assert.Equal(t, "Supplier A", r.name("KPMG Australia"))
+	assert.Equal(t, "Supplier B", r.name("Deloitte"))
+	assert.Equal(t, "Supplier A", r.name("KPMG  AUSTRALIA"), "names that normalise alike share a pseudonym")
+	assert.Equal(t, "Supplier C", r.name("KPMG"))
+
+	assert.Equal(t, "Advice from Supplier A and Supplier C", r.text("Advice from KPMG Australia and KPMG"))
+	assert.Equal(t, "/Search?SupplierName=Supplier+A", r.text("/Search?SupplierName=KPMG+Australia"))
+	assert.Equal(t, "Supplier A (Supplier C) invoice", r.text("kpmg australia (Kpmg) invoice"), "matching ignores case")
+	assert.Equal(t, "?SupplierName=Supplier+A", r.text("?SupplierName=kpmg+AUSTRALIA"))
+
+	c := r.contract(sampleContract)
+	assert.Equal(t, "Supplier D", c.Supplier_Name)
+	assert.Equal(t, "KPMG Peat Marwick - ACT", sampleContract.Supplier_Name, "the original is not modified")
+
+	var none *supplierRedactor
+	assert.Same(t, sampleContract, none.contract(sampleContract))
+}
+
+func TestRedactionKey(t *testing.T) {
+	r := newSupplierRedactor()
+	for _, s := range []string{"A1", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M", "N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y", "Z", "AA"} {
+		r.name(s)
+	}
+	r.name("a1")
+	path := filepath.Join(t.TempDir(), "key.csv")
+	assert.NoError(t, r.writeKey(path))
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, "pseudonym,supplier", lines[0])
+	assert.Equal(t, "Supplier A,A1", lines[1])
+	assert.Equal(t, "Supplier A,a1", lines[2])
+	assert.Equal(t, "Supplier AA,AA", lines[len(lines)-1], "pseudonyms are in sequence order")
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the key is private to its owner")
+}
+
+func TestRedactionKeyTightensExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("old key\n"), 0o644))
+	r := newSupplierRedactor()
+	r.name("KPMG")
+	assert.NoError(t, r.writeKey(path))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	data, _ := os.ReadFile(path)
+	assert.Equal(t, "pseudonym,supplier\nSupplier A,KPMG\n", string(data))
+}
+
+// TestRedactedArtifactsDoNotLeak runs every output that can show a supplier
+// with redaction on and checks no raw name appears.
+func TestRedactedArtifactsDoNotLeak(t *testing.T) {
+	base := serveFixtures(t)
+	dir := t.TempDir()
+	artifacts := map[string]string{}
+	run := func(name string, args ...string) {
+		args = append(args, "--base-url", base, "--c", "KPMG", "--no-progress", "--redact-suppliers")
+		out, errOut, err := runRoot(t, args...)
+		assert.NoError(t, err, name)
+		artifacts[name] = out + errOut
+	}
+	for _, format := range []string{"human", "jsonl", "csv", "bulkfile"} {
+		run(format, "--output", format)
+	}
+	summary := filepath.Join(dir, "run.json")
+	run("summary", "--output", "quiet", "--summary-file", summary)
+	run("report", "report", "agency", "--d", "Defence")
+	run("breakdown", "breakdown", "--by", "supplier")
+	run("contract", "contract", "CN3500001", "--history")
+	key := filepath.Join(dir, "key.csv")
+	run("concentration", "concentration", "--d", "Defence", "--redaction-key", key)
+	run("top-suppliers", "top-suppliers", "--json")
+	run("compare", "compare", "companies", "--left", "KPMG", "--right", "Deloitte")
+	run("committed", "committed", "--by", "supplier", "--as-of", "2019-01-01")
+	data, _ := os.ReadFile(summary)
+	artifacts["summary file"] = string(data)
+
+	for name, text := range artifacts {
+		assert.NotContains(t, strings.ToUpper(text), "KPMG", name)
+		assert.NotEmpty(t, text, name)
+	}
+	assert.Contains(t, artifacts["breakdown"], "Supplier ")
+	assert.NotContains(t, artifacts["compare"], "Deloitte")
+	assert.Regexp(t, `^\s+Supplier A\s+Supplier B\s`, artifacts["compare"], "left is labelled before right")
+	var top []supplierTotal
+	assert.NoError(t, json.Unmarshal([]byte(artifacts["top-suppliers"]), &top))
+	assert.Len(t, top, 1, "variants are grouped before redaction")
+	assert.Equal(t, 3, top[0].Count)
+	assert.True(t, strings.HasPrefix(top[0].Name, "Supplier "))
+
+	data, _ = os.ReadFile(key)
+	assert.Contains(t, string(data), "KPMG", "the key file maps pseudonyms back to real names")
+}
+
+func TestRedactionKeyRequiresRedaction(t *testing.T) {
+	_, _, err := runRoot(t, "--redaction-key", filepath.Join(t.TempDir(), "key.csv"))
+	assert.Error(t, err)
+}
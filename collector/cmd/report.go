@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+const (
+	reportTopSuppliers     = 20
+	reportLargestContracts = 10
+)
+
+// filterFY keeps contracts published in the financial year fy; an empty fy
+// keeps everything.
+func filterFY(contracts []*contract, fy string) []*contract {
+	if fy == "" {
+		return contracts
+	}
+	kept := []*contract{}
+	for _, c := range contracts {
+		if fyKey(c) == fy {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// mdCell escapes text for a Markdown table cell.
+func mdCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// reportAgencyName is the agency name to head a report with: the name the
+// matched contracts most often carry, ties going to the alphabetically
+// first, so a partial filter such as "Defence" reads "Department of
+// Defence". With no contracts it falls back to the filter itself.
+func reportAgencyName(filter string, contracts []*contract) string {
+	counts := map[string]int{}
+	for _, c := range contracts {
+		if c.Agency != "" {
+			counts[c.Agency]++
+		}
+	}
+	name, best := filter, 0
+	for a, n := range counts {
+		if n > best || (n == best && a < name) {
+			name, best = a, n
+		}
+	}
+	return name
+}
+
+// writeAgencyReport renders a Markdown spend report for one agency.
+func writeAgencyReport(w io.Writer, agency, fy string, contracts []*contract) error {
+	period := "all years"
+	if fy != "" {
+		period = "FY " + fy
+	}
+	total := decimal.Zero
+	for _, c := range contracts {
+		total = total.Add(c.Contract_Value)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s procurement report, %s\n\n", agency, period)
+	fmt.Fprintf(&b, "**Total spend:** %s across %d contracts (by publish date).\n\n", formatMoney(total), len(contracts))
+
+	suppliers := groupContracts(contracts, supplierKey)
+	sortByTotalDesc(suppliers)
+	if len(suppliers) > reportTopSuppliers {
+		suppliers = suppliers[:reportTopSuppliers]
+	}
+	fmt.Fprintf(&b, "## Top %d suppliers\n\n", reportTopSuppliers)
+	b.WriteString("| Rank | Supplier | Total | Contracts |\n|---:|---|---:|---:|\n")
+	for i, g := range suppliers {
+		fmt.Fprintf(&b, "| %d | %s | %s | %d |\n", i+1, mdCell(g.Key), formatMoney(g.Total), g.Count)
+	}
+
+	b.WriteString("\n## Monthly spend\n\n| Month | Total | Contracts |\n|---|---:|---:|\n")
+	for _, g := range groupContracts(contracts, monthKey) {
+		fmt.Fprintf(&b, "| %s | %s | %d |\n", g.Key, formatMoney(g.Total), g.Count)
+	}
+
+	largest := append([]*contract{}, contracts...)
+	sort.SliceStable(largest, func(i, j int) bool {
+		return largest[i].Contract_Value.GreaterThan(largest[j].Contract_Value)
+	})
+	if len(largest) > reportLargestContracts {
+		largest = largest[:reportLargestContracts]
+	}
+	b.WriteString("\n## Largest contracts\n\n| CN ID | Supplier | Category | Published | Value |\n|---|---|---|---|---:|\n")
+	for _, c := range largest {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", mdCell(c.CN_ID), mdCell(c.Supplier_Name), mdCell(c.Category), c.Publish_Date, formatMoney(c.Contract_Value))
+	}
+
+	b.WriteString("\n_Limited-tender share is not reported: AusTender search results do not include the procurement method._\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate shareable spend reports",
+}
+
+var reportAgencyCmd = &cobra.Command{
+	Use:   "agency",
+	Short: "Markdown spend report for one agency (--d)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fy, _ := cmd.Flags().GetString("fy")
+		output, _ := cmd.Flags().GetString("output")
+		if output != "md" {
+			return fmt.Errorf("unsupported report output %q (want md)", output)
+		}
+		if fy != "" {
+			start, err := parseFYLabel(fy)
+			if err != nil {
+				return err
+			}
+			fy = fyLabelFromStart(start)
+		}
+		cfg := resolveConfig(os.Environ())
+		req := requestFromFlags(cmd, cfg)
+		if req.Agency == "" {
+			return fmt.Errorf("an agency is required (--d)")
+		}
+
+		res, err := quietSearch(cmd, req)
+		if err != nil {
+			return err
+		}
+		if err := writeAgencyReport(cmd.OutOrStdout(), reportAgencyName(req.Agency, res.Contracts), fy, filterFY(res.Contracts, fy)); err != nil {
+			return err
+		}
+		if note := gstNote(req.NormaliseGST); note != "" {
+			// Its own paragraph, so Markdown doesn't read it as a table row
+			fmt.Fprintf(cmd.OutOrStdout(), "\n_%s_\n", note)
+		}
+		return nil
+	},
+}
+
+func init() {
+	reportAgencyCmd.Flags().String("fy", "", "Financial year to report on, e.g. 2023-24")
+	reportAgencyCmd.Flags().String("output", "md", "Report format: md")
+	reportCmd.AddCommand(reportAgencyCmd)
+	rootCmd.AddCommand(reportCmd)
+}
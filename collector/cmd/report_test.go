@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// assertGolden compares got with testdata/name, rewriting it under -update.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		assert.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+	want, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func reportFixture() []*contract {
+	mk := func(id, supplier, date, value string) *contract {
+		return &contract{
+			CN_ID:          id,
+			Agency:         "Services Australia",
+			Supplier_Name:  supplier,
+			Category:       "IT services",
+			Publish_Date:   date,
+			Contract_Value: decimal.RequireFromString(value),
+		}
+	}
+	return []*contract{
+		mk("CN1", "Accenture", "3-Jul-2023", "1500000"),
+		mk("CN2", "Deloitte", "15-Jul-2023", "250000.50"),
+		mk("CN3", "Accenture", "2-Feb-2024", "400000"),
+		mk("CN4", "Data | Co", "30-Jun-2024", "99.99"),
+		mk("CN5", "Deloitte", "1-Jul-2024", "700000"),
+	}
+}
+
+func TestFilterFY(t *testing.T) {
+	assert.Len(t, filterFY(reportFixture(), "2023-24"), 4)
+	assert.Len(t, filterFY(reportFixture(), ""), 5)
+}
+
+func TestReportAgencyName(t *testing.T) {
+	contracts := []*contract{
+		{Agency: "Department of Defence"},
+		{Agency: "Defence Housing Australia"},
+		{Agency: "Department of Defence"},
+	}
+	assert.Equal(t, "Department of Defence", reportAgencyName("defence", contracts))
+	assert.Equal(t, "Defence Housing Australia", reportAgencyName("defence", contracts[:2]), "ties go to the first name alphabetically")
+	assert.Equal(t, "defence", reportAgencyName("defence", nil))
+}
+
+func TestAgencyReportGolden(t *testing.T) {
+	var buf bytes.Buffer
+	contracts := filterFY(reportFixture(), "2023-24")
+	assert.NoError(t, writeAgencyReport(&buf, "Services Australia", "2023-24", contracts))
+	assertGolden(t, "report_agency.golden.md", buf.Bytes())
+}
+
+func TestReportAgencyAcceptsFullFYLabel(t *testing.T) {
+	base := serveFixtures(t)
+	out, _, err := runRoot(t, "report", "agency", "--base-url", base, "--c", "KPMG", "--d", "Defence", "--fy", "2018-2019", "--no-progress")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "# Department of Defence procurement report, FY 2018-19")
+
+	_, _, err = runRoot(t, "report", "agency", "--base-url", base, "--d", "Defence", "--fy", "2018-20")
+	assert.Error(t, err)
+}
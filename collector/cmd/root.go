@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -11,24 +13,183 @@ var rootCmd = &cobra.Command{
 	Use:   "austender",
 	Short: "Get austender summaries",
 	Long:  `Austender CLI tool to scrape and persist tender awards data for various companies`,
-	Run: func(cmd *cobra.Command, args []string) {
-		companyName, _ := cmd.Flags().GetString("c")
-		keywordVal, _ := cmd.Flags().GetString("d")
-		agencyVal, _ := cmd.Flags().GetString("k")
+	// Execute prints returned errors; usage is only noise for run failures
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		redactor = nil
+		redact, _ := cmd.Flags().GetBool("redact-suppliers")
+		keyPath, _ := cmd.Flags().GetString("redaction-key")
+		if keyPath != "" && !redact {
+			return fmt.Errorf("--redaction-key requires --redact-suppliers")
+		}
+		if redact {
+			redactor = newSupplierRedactor()
+		}
+		if path, _ := cmd.Flags().GetString("portfolio-map"); path != "" {
+			m, err := loadPortfolioMap(path)
+			if err != nil {
+				return err
+			}
+			portfolios = m
+		}
+		return nil
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		if keyPath, _ := cmd.Flags().GetString("redaction-key"); keyPath != "" && redactor != nil {
+			return redactor.writeKey(keyPath)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		summaryFile, _ := cmd.Flags().GetString("summary-file")
+		output, _ := cmd.Flags().GetString("output")
+		outFile, _ := cmd.Flags().GetString("out-file")
+		timings, _ := cmd.Flags().GetBool("timings")
+		cfg := resolveConfig(os.Environ())
+		out := cmd.OutOrStdout()
+		var export *exportFile
+		if outFile != "" {
+			var err error
+			if export, err = createExportFile(outFile); err != nil {
+				return err
+			}
+			out = export
+		}
+		sink, err := sinkFromFlags(cmd, cfg, output, out)
+		if err != nil {
+			if export != nil {
+				export.Abort()
+			}
+			return err
+		}
+		bulk, _ := sink.(*bulkSink)
 
-		scrapeAncap(keywordVal, companyName, agencyVal)
+		req := requestFromFlags(cmd, cfg)
+		if redactor != nil {
+			// The company filter is a supplier name too
+			redactor.name(req.Company)
+			sink = &redactingSink{next: sink, r: redactor}
+		}
+		started := time.Now()
+		res, err := scrapeAncap(req, sink)
+		if bulk != nil && err == nil {
+			err = bulk.Err()
+		}
+		if export != nil {
+			if err != nil {
+				export.Abort()
+			} else {
+				err = export.Commit()
+			}
+		}
+		if timings && err == nil {
+			writeTimings(cmd.ErrOrStderr(), res.Stats)
+		}
+		if summaryFile != "" {
+			if werr := writeRunSummary(summaryFile, redactedRunSummary(req, started, res, err)); werr != nil && err == nil {
+				err = werr
+			}
+		}
+		return err
 	},
 }
 
+// requestFromFlags builds the search request from the shared persistent
+// flags, taking settings the flags leave unset from cfg.
+func requestFromFlags(cmd *cobra.Command, cfg config) searchRequest {
+	companyName, _ := cmd.Flags().GetString("c")
+	agencyVal, _ := cmd.Flags().GetString("d")
+	keywordVal, _ := cmd.Flags().GetString("k")
+	portfolio, _ := cmd.Flags().GetString("portfolio")
+	gstMode, _ := cmd.Flags().GetString("normalise-gst")
+	pageRetries, _ := cmd.Flags().GetInt("page-retries")
+	wordMatchUnder, _ := cmd.Flags().GetInt("word-match-under")
+	baseURL, _ := cmd.Flags().GetString("base-url")
+	if baseURL == "" {
+		baseURL = cfg.BaseURL
+	}
+	return searchRequest{
+		Keyword:        keywordVal,
+		Company:        companyName,
+		Agency:         agencyVal,
+		Portfolio:      portfolio,
+		NormaliseGST:   gstMode,
+		BaseURL:        baseURL,
+		PageRetries:    pageRetries,
+		WordMatchUnder: wordMatchUnder,
+	}
+}
+
+// sinkFromFlags returns the sink for --output, writing results to out.
+// OpenSearch credentials come from cfg.
+func sinkFromFlags(cmd *cobra.Command, cfg config, output string, out io.Writer) (OutputSink, error) {
+	if output == "bulkfile" || output == "opensearch" {
+		index, _ := cmd.Flags().GetString("index")
+		endpoint, _ := cmd.Flags().GetString("endpoint")
+		batch, _ := cmd.Flags().GetInt("bulk-batch")
+		timeout, _ := cmd.Flags().GetDuration("bulk-timeout")
+		cfg := bulkConfig{
+			Index:     index,
+			Endpoint:  endpoint,
+			BatchSize: batch,
+			Timeout:   timeout,
+			User:      cfg.OpenSearchUser,
+			Password:  cfg.OpenSearchPassword,
+			APIKey:    cfg.OpenSearchAPIKey,
+		}
+		bulk, err := newBulkSink(output, out, cmd.ErrOrStderr(), progressFromFlags(cmd), cfg)
+		if err != nil {
+			return nil, err
+		}
+		return bulk, nil
+	}
+	return newOutputSink(output, out, cmd.ErrOrStderr(), progressFromFlags(cmd))
+}
+
+// progressFromFlags returns the progress printer, or nil under --no-progress.
+func progressFromFlags(cmd *cobra.Command) *progressPrinter {
+	if noProgress, _ := cmd.Flags().GetBool("no-progress"); noProgress {
+		return nil
+	}
+	return newProgressPrinter(cmd.ErrOrStderr())
+}
+
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+	if err := execute(); err != nil {
 		os.Exit(1)
 	}
 }
 
+// execute runs the CLI and prints any error on stderr, keeping it out of
+// results redirected from stdout.
+func execute() error {
+	err := rootCmd.Execute()
+	if err != nil {
+		fmt.Fprintln(rootCmd.ErrOrStderr(), err)
+	}
+	return err
+}
+
 func init() {
-	rootCmd.PersistentFlags().String("c", "", "Company to scan")
-	rootCmd.PersistentFlags().String("d", "", "Department to scan")
-	rootCmd.PersistentFlags().String("k", "", "Keywords to scan")
+	rootCmd.PersistentFlags().StringP("c", "c", "", "Company to scan")
+	rootCmd.PersistentFlags().StringP("d", "d", "", "Department to scan")
+	rootCmd.PersistentFlags().StringP("k", "k", "", "Keywords to scan")
+	rootCmd.PersistentFlags().String("portfolio", "", "Portfolio to scan, e.g. Defence; expands to its agencies")
+	rootCmd.PersistentFlags().String("portfolio-map", "", "CSV of agency,portfolio rows replacing the built-in portfolio map")
+	rootCmd.PersistentFlags().String("normalise-gst", "", "Normalise amounts to GST inclusive or exclusive")
+	rootCmd.Flags().String("output", "human", "Output format: human, jsonl, csv, quiet, bulkfile or opensearch")
+	rootCmd.PersistentFlags().String("base-url", "", "Site to search instead of AusTender (default $AUSTENDER_BASE_URL or https://www.tenders.gov.au)")
+	rootCmd.PersistentFlags().Int("word-match-under", 5, "Company filters shorter than this many characters match whole words only; 0 always matches substrings")
+	rootCmd.PersistentFlags().Int("page-retries", 2, "Times to retry a failed result page at the end of the run")
+	rootCmd.PersistentFlags().Bool("no-progress", false, "Disable progress output on stderr")
+	rootCmd.Flags().String("out-file", "", "Write output to this file instead of stdout")
+	rootCmd.Flags().String("index", defaultBulkIndex, "Index name for bulkfile and opensearch output")
+	rootCmd.Flags().String("endpoint", "", "OpenSearch/Elasticsearch URL for opensearch output")
+	rootCmd.Flags().Int("bulk-batch", 500, "Notices per _bulk request for opensearch output")
+	rootCmd.Flags().Duration("bulk-timeout", 30*time.Second, "Timeout per _bulk request for opensearch output; 0 waits indefinitely")
+	rootCmd.Flags().Bool("timings", false, "Print how long each phase of the run took on stderr")
+	rootCmd.PersistentFlags().Bool("redact-suppliers", false, "Replace supplier names with per-run pseudonyms such as \"Supplier A\"")
+	rootCmd.PersistentFlags().String("redaction-key", "", "Write the pseudonym to supplier name mapping to this CSV file (internal use)")
+	rootCmd.PersistentFlags().String("summary-file", "", "Write a JSON run summary to this path")
 }
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+// runRoot executes the CLI with args and returns stdout and stderr
+// separately. Flags are reset first since cobra keeps them between runs.
+func runRoot(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	resetFlags(rootCmd)
+	var out, errOut bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&errOut)
+	rootCmd.SetArgs(args)
+	err := rootCmd.Execute()
+	return out.String(), errOut.String(), err
+}
+
+func resetFlags(c *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		// Slice defaults print as "[a,b]", which Set would take literally
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			def := []string{}
+			if trimmed := strings.Trim(f.DefValue, "[]"); trimmed != "" {
+				def = strings.Split(trimmed, ",")
+			}
+			sv.Replace(def)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	c.PersistentFlags().VisitAll(reset)
+	c.Flags().VisitAll(reset)
+	for _, sub := range c.Commands() {
+		resetFlags(sub)
+	}
+}
+
+func TestRootStdoutHasNoProgress(t *testing.T) {
+	base := serveFixtures(t)
+	out, errOut, err := runRoot(t, "--base-url", base, "--c", "KPMG")
+	assert.NoError(t, err)
+	assert.NotContains(t, out, "\r")
+	assert.NotContains(t, out, "Scraped")
+	assert.Contains(t, out, "Total Contract:$742,560.50")
+	assert.Contains(t, errOut, "Scraped 2/2 pages (100%)")
+}
+
+func TestRootNoProgress(t *testing.T) {
+	base := serveFixtures(t)
+	_, errOut, err := runRoot(t, "--base-url", base, "--c", "KPMG", "--no-progress")
+	assert.NoError(t, err)
+	assert.Empty(t, errOut)
+}
+
+func TestRootTimings(t *testing.T) {
+	base := serveFixtures(t)
+	_, errOut, err := runRoot(t, "--base-url", base, "--c", "KPMG", "--no-progress", "--timings")
+	assert.NoError(t, err)
+	assert.Regexp(t, `^Timings: fetch \S+, aggregate \S+ \(2 pages, 0 retried\)\n$`, errOut)
+}
+
+func TestExecuteKeepsErrorsOffStdout(t *testing.T) {
+	base := serveFixtures(t)
+	resetFlags(rootCmd)
+	var out, errOut bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&errOut)
+	rootCmd.SetArgs([]string{"--base-url", base, "--c", "KPMG", "--output", "csv", "--normalise-gst", "bogus"})
+	assert.Error(t, execute())
+	assert.Empty(t, out.String(), "results on stdout stay clean")
+	assert.Contains(t, errOut.String(), "bogus")
+}
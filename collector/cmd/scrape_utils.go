@@ -3,12 +3,14 @@ package cmd
 import (
 	"fmt"
 	"net/url"
-	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gocolly/colly"
 	"github.com/leekchan/accounting"
 	"github.com/shopspring/decimal"
+	"github.com/whatnick/austender_analyser/collector/parse"
 )
 
 // Fields
@@ -24,56 +26,221 @@ import (
 	Supplier Name:KPMG Peat Marwick - ACT
 */
 type contract struct {
-	CN_ID           string
-	Amends          string
-	Agency          string
-	Publish_Date    string
-	Category        string
-	Contract_Period string
-	Contract_Value  decimal.Decimal
-	ATM_ID          string
-	SON_ID          string
-	Supplier_Name   string
+	CN_ID           string          `json:"cnId"`
+	Amends          string          `json:"amends,omitempty"`
+	Agency          string          `json:"agency"`
+	Publish_Date    string          `json:"publishDate"`
+	Category        string          `json:"category"`
+	Contract_Period string          `json:"contractPeriod"`
+	Contract_Value  decimal.Decimal `json:"contractValue"`
+	ATM_ID          string          `json:"atmId,omitempty"`
+	SON_ID          string          `json:"sonId,omitempty"`
+	Supplier_Name   string          `json:"supplierName"`
+	// Portfolio of Agency from the portfolio map, if known
+	Portfolio string `json:"portfolio,omitempty"`
+	// Whether Contract_Value as published includes GST
+	Amount_Includes_GST bool `json:"amountIncludesGst"`
 }
 
+// cleanNum parses a scraped amount, treating unparseable text as zero so
+// the notice is skipped rather than counted at a garbage value.
 func cleanNum(s string) decimal.Decimal {
-	r := regexp.MustCompile(`[^0-9-. ]`) // Remove anything thats not a number,space or decimal
-	num := r.ReplaceAllString(s, "${1}")
-	num = strings.Trim(num, " ")
-	v, _ := decimal.NewFromString(num)
+	v, _ := parse.ParseMoney(s)
 	return v
 }
 
-func scrapeAncap(keywordVal, companyName, agencyVal string) {
-	collector := colly.NewCollector(colly.Async(true))
-	contracts := []*contract{}
+// searchRequest holds the filters for a single scrape run.
+type searchRequest struct {
+	Keyword string `json:"keyword"`
+	Company string `json:"company"`
+	Agency  string `json:"agency"`
+	// Portfolio whose agencies to keep; see portfolioMap
+	Portfolio string `json:"portfolio,omitempty"`
+	// GST basis to normalise amounts to; empty keeps published values
+	NormaliseGST string `json:"normaliseGst,omitempty"`
+	// Site to search instead of AusTender, e.g. a mirror; see resolveBaseURL
+	BaseURL string `json:"baseUrl,omitempty"`
+	// Times a failed result page is re-queued at the end of the run
+	PageRetries int `json:"pageRetries,omitempty"`
+	// Company filters shorter than this match whole words; see companyMatch
+	WordMatchUnder int `json:"wordMatchUnder,omitempty"`
+	// Keep every notice per contract in searchResult.History
+	TrackAmendments bool `json:"trackAmendments,omitempty"`
+}
+
+// searchResult is what a scrape run produced.
+type searchResult struct {
+	// One entry per contract, at its latest amended value
+	Contracts []*contract
+	Total     decimal.Decimal
+	Totals    searchTotals
+	Warnings  []string
+	// Contracts parsed from result pages before the local agency filter
+	Observed int
+	Stats    runStats
+	// Every notice per canonical contract ID, when TrackAmendments is set
+	History map[string][]*contract
+	// Largest suppliers by total, and the publish dates the contracts span
+	TopSuppliers []supplierTotal
+	DateRange    *publishRange
+	// Matched notices per company match mode, when a company filter is set
+	CompanyMatches map[string]int
+}
+
+func (r searchRequest) hasFilters() bool {
+	return r.Keyword != "" || r.Company != "" || r.Agency != "" || r.Portfolio != ""
+}
+
+// zeroResultWarning explains an empty result for a filtered search. No
+// contracts on the pages at all suggests blocking or a changed page layout
+// rather than a genuine absence of contracts. agencies are the distinct
+// agencies seen, used to suggest a fix for a misspelt agency filter.
+// companyKept counts the contracts that passed the company filter, so the
+// warning names whichever filter dropped them all.
+func zeroResultWarning(req searchRequest, pages, observed, companyKept int, agencies []string) string {
+	if observed == 0 {
+		return fmt.Sprintf("AusTender returned no contracts across %d page(s); check the filter spelling, or the site may be blocking requests or have changed its page layout", pages)
+	}
+	if companyKept == 0 {
+		return fmt.Sprintf("AusTender returned %d contract(s) but none matched company filter %q as whole words; see --word-match-under", observed, req.Company)
+	}
+	msg := fmt.Sprintf("AusTender returned %d contract(s) but none matched agency filter %q", observed, req.Agency)
+	if req.Portfolio != "" {
+		msg += fmt.Sprintf(" in portfolio %q", req.Portfolio)
+	}
+	if req.Agency != "" {
+		if s := suggestName(req.Agency, agencies); s != "" {
+			msg += fmt.Sprintf("; did you mean %q?", s)
+		}
+	}
+	return msg
+}
+
+func formatMoney(d decimal.Decimal) string {
 	ac := accounting.Accounting{Symbol: "$", Precision: 2}
-	contractSum := decimal.New(0, 0)
+	return ac.FormatMoneyDecimal(d)
+}
+
+const (
+	defaultBaseURL = "https://www.tenders.gov.au"
+	// baseURLEnv overrides the AusTender site, e.g. for a mirror
+	baseURLEnv = "AUSTENDER_BASE_URL"
+)
+
+// resolveBaseURL picks the site to search: the override, which the CLI
+// takes from --base-url or the environment, else AusTender itself. It must
+// be an absolute http(s) URL.
+func resolveBaseURL(base string) (string, error) {
+	if base == "" {
+		return defaultBaseURL, nil
+	}
+	u, err := url.Parse(base)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid base URL %q (want an absolute http or https URL)", base)
+	}
+	return strings.TrimRight(base, "/"), nil
+}
+
+// pageRetryBackoff is the pause before the first page retry round; it
+// doubles for each further round.
+var pageRetryBackoff = 2 * time.Second
+
+// failedPage is a result page whose last fetch failed.
+type failedPage struct {
+	req *colly.Request
+	err error
+}
+
+// searchURL builds the contract notice search URL for req on the site base.
+func searchURL(base string, req searchRequest) string {
 	params := url.Values{}
 	params.Add("SearchFrom", "CnSearch")
 	params.Add("Type", "Cn")
 	params.Add("AgencyStatus", "-1")
 	params.Add("KeywordTypeSearch", "AllWord")
 	params.Add("DateType", "Publish Date")
-	params.Add("Keyword", keywordVal)
-	params.Add("SupplierName", companyName)
-	requestURL := "https://www.tenders.gov.au/Search/CnAdvancedSearch?" + params.Encode()
+	params.Add("Keyword", req.Keyword)
+	params.Add("SupplierName", req.Company)
+	return base + "/Search/CnAdvancedSearch?" + params.Encode()
+}
+
+// scrapeAncap runs a search and reports matches, progress, warnings and the
+// total through sink. Sink methods are never called concurrently.
+func scrapeAncap(req searchRequest, sink OutputSink) (searchResult, error) {
+	companyName, agencyVal := req.Company, req.Agency
+	if err := validateGSTMode(req.NormaliseGST); err != nil {
+		return searchResult{}, err
+	}
+	base, err := resolveBaseURL(req.BaseURL)
+	if err != nil {
+		return searchResult{}, err
+	}
+	// A portfolio filter expands to its member agencies before matching
+	var portfolioAgencies map[string]bool
+	if req.Portfolio != "" {
+		members := portfolios.agencies(req.Portfolio)
+		if len(members) == 0 {
+			if s := suggestName(req.Portfolio, portfolios.names()); s != "" {
+				return searchResult{}, fmt.Errorf("unknown portfolio %q; did you mean %q?", req.Portfolio, s)
+			}
+			return searchResult{}, fmt.Errorf("unknown portfolio %q", req.Portfolio)
+		}
+		portfolioAgencies = map[string]bool{}
+		for _, a := range members {
+			portfolioAgencies[a] = true
+		}
+	}
+	collector := colly.NewCollector(colly.Async(true))
+	contracts := []*contract{}
+	warnings := []string{}
+	pagesRequested, pagesDone, observed, companyKept := 0, 0, 0, 0
+	seenPages := map[string]bool{}
+	seenAgencies := map[string]bool{}
+	var companyMatches map[string]int
+	if companyName != "" {
+		companyMatches = map[string]int{}
+	}
+	failed := map[string]failedPage{}
+	var mu sync.Mutex
+	requestURL := searchURL(base, req)
+
+	collector.OnRequest(func(r *colly.Request) {
+		mu.Lock()
+		if page := r.URL.String(); !seenPages[page] {
+			seenPages[page] = true
+			pagesRequested++
+		}
+		mu.Unlock()
+	})
+
+	collector.OnScraped(func(r *colly.Response) {
+		mu.Lock()
+		delete(failed, r.Request.URL.String())
+		pagesDone++
+		sink.OnProgress(pagesDone, pagesRequested)
+		mu.Unlock()
+	})
 
 	collector.OnHTML("a[href]", func(e *colly.HTMLElement) {
-		url := e.Attr("href")
-		if strings.Contains(url, "SupplierName="+companyName) {
-			//fmt.Printf(url)
+		href := e.Attr("href")
+		link, err := url.Parse(href)
+		if err != nil || !link.Query().Has("SupplierName") {
+			return
+		}
+		if nameContains(link.Query().Get("SupplierName"), companyName) {
 			// Visit all search bread crumbs
-			e.Request.Visit(url)
+			e.Request.Visit(href)
 		}
 	})
 
 	collector.OnHTML(".col-sm-8", func(e *colly.HTMLElement) {
-		c := &contract{}
+		c := &contract{Amount_Includes_GST: federalAmountIncludesGST}
 		e.ForEach(".list-desc", func(_ int, el *colly.HTMLElement) {
 			switch el.ChildText("span") {
 			case "CN ID:":
 				c.CN_ID = el.ChildText(".list-desc-inner")
+			case "Amends:":
+				c.Amends = el.ChildText(".list-desc-inner")
 			case "Agency:":
 				c.Agency = el.ChildText(".list-desc-inner")
 			case "Publish Date:":
@@ -94,18 +261,108 @@ func scrapeAncap(keywordVal, companyName, agencyVal string) {
 			}
 		})
 		if c.Contract_Value.GreaterThan(decimal.New(0, 0)) {
-			if strings.Contains(c.Agency, agencyVal) {
-				fmt.Println(c)
+			supplierOK, mode := companyMatch(c.Supplier_Name, companyName, req.WordMatchUnder)
+			mu.Lock()
+			observed++
+			if supplierOK {
+				companyKept++
+			}
+			seenAgencies[c.Agency] = true
+			mu.Unlock()
+			inPortfolio := portfolioAgencies == nil || portfolioAgencies[normalizeName(c.Agency)]
+			if supplierOK && nameContains(c.Agency, agencyVal) && inPortfolio {
+				if p := portfolios.portfolioOf(c.Agency); p != unmappedPortfolio {
+					c.Portfolio = p
+				}
+				c.Contract_Value = normaliseGST(c.Contract_Value, c.Amount_Includes_GST, req.NormaliseGST)
+				if req.NormaliseGST != gstAsPublished {
+					c.Amount_Includes_GST = req.NormaliseGST == gstInclusive
+				}
+				mu.Lock()
+				if mode != "" {
+					companyMatches[mode]++
+				}
 				contracts = append(contracts, c)
+				sink.OnMatch(c)
+				mu.Unlock()
 			}
 		}
 	})
 
-	collector.Visit(requestURL)
+	collector.OnError(func(r *colly.Response, err error) {
+		mu.Lock()
+		failed[r.Request.URL.String()] = failedPage{req: r.Request, err: err}
+		mu.Unlock()
+	})
+
+	stats := runStats{}
+	endFetch := stats.phase("fetch")
+	if err := collector.Visit(requestURL); err != nil {
+		return searchResult{}, err
+	}
 	collector.Wait()
-	for _, c := range contracts {
-		contractSum = contractSum.Add(c.Contract_Value)
+	endFetch()
+
+	// Re-queue failed pages in rounds so one flaky page doesn't drop its
+	// contracts from the total.
+	backoff := pageRetryBackoff
+	endRetry := func() {}
+	if req.PageRetries > 0 && len(failed) > 0 {
+		endRetry = stats.phase("retry")
+	}
+	for round := 0; round < req.PageRetries && len(failed) > 0; round++ {
+		time.Sleep(backoff)
+		backoff *= 2
+		retries := []*colly.Request{}
+		for _, f := range failed {
+			retries = append(retries, f.req)
+		}
+		for _, r := range retries {
+			r.Retry()
+		}
+		stats.RetriedPages += len(retries)
+		collector.Wait()
+	}
+	endRetry()
+	for page, f := range failed {
+		msg := fmt.Sprintf("%s: %v", page, f.err)
+		warnings = append(warnings, msg)
+		sink.OnWarning(msg)
+		pagesDone++
+		sink.OnProgress(pagesDone, pagesRequested)
+	}
+	if len(contracts) == 0 && req.hasFilters() {
+		agencies := []string{}
+		for a := range seenAgencies {
+			agencies = append(agencies, a)
+		}
+		msg := zeroResultWarning(req, pagesRequested, observed, companyKept, agencies)
+		warnings = append(warnings, msg)
+		sink.OnWarning(msg)
+	}
+	if msg := undatedWarning(contracts); msg != "" {
+		warnings = append(warnings, msg)
+		sink.OnWarning(msg)
+	}
+	endAggregate := stats.phase("aggregate")
+	var history map[string][]*contract
+	if req.TrackAmendments {
+		history = amendmentHistories(contracts)
 	}
-	sumValue := ac.FormatMoney(contractSum)
-	fmt.Println("Total Contract:" + sumValue)
+	contracts, totals := aggregateContracts(contracts)
+	endAggregate()
+	stats.Pages = pagesRequested
+	sink.OnTotal(totals, gstNote(req.NormaliseGST))
+	return searchResult{
+		Contracts:      contracts,
+		Total:          totals.Final,
+		Totals:         totals,
+		Warnings:       warnings,
+		Observed:       observed,
+		Stats:          stats,
+		History:        history,
+		TopSuppliers:   topSuppliers(contracts, resultTopSuppliers),
+		DateRange:      publishDateRange(contracts),
+		CompanyMatches: companyMatches,
+	}, nil
 }
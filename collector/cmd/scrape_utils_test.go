@@ -1,13 +1,235 @@
 package cmd
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCleanNum(t *testing.T) {
-	zero, _ := decimal.NewFromString("")
-	assert.Equal(t, cleanNum("BlahBlah"), zero, "Arbitrary strings parse to zero")
+	assert.True(t, cleanNum("BlahBlah").IsZero(), "Arbitrary strings parse to zero")
+	assert.True(t, cleanNum("$542,560.00").Equal(decimal.NewFromInt(542560)))
+}
+
+// recordingSink captures sink events for assertions.
+type recordingSink struct {
+	progress []int
+	matches  []*contract
+	warnings []string
+	total    decimal.Decimal
+	note     string
+	totals   int
+}
+
+func (s *recordingSink) OnProgress(done, total int) { s.progress = append(s.progress, done) }
+func (s *recordingSink) OnMatch(c *contract)        { s.matches = append(s.matches, c) }
+func (s *recordingSink) OnWarning(msg string)       { s.warnings = append(s.warnings, msg) }
+func (s *recordingSink) OnTotal(totals searchTotals, note string) {
+	s.total, s.note = totals.Final, note
+	s.totals++
+}
+
+// serveFixtures starts a stub AusTender serving the testdata pages, keyed
+// by the Page query parameter, and returns its base URL.
+func serveFixtures(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("Page")
+		if page == "" {
+			page = "1"
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", "cn_search_page"+page+".html"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestScrapeAncapWritesThroughSink(t *testing.T) {
+	base := serveFixtures(t)
+	sink := &recordingSink{}
+
+	res, err := scrapeAncap(searchRequest{BaseURL: base, Company: "KPMG"}, sink)
+	assert.NoError(t, err)
+	assert.Len(t, sink.matches, 3)
+	assert.Equal(t, 1, sink.totals)
+	assert.True(t, sink.total.Equal(decimal.RequireFromString("742560.50")))
+	assert.True(t, res.Total.Equal(sink.total))
+	assert.Equal(t, []int{1, 2}, sink.progress)
+	assert.Empty(t, sink.warnings)
+}
+
+func TestScrapeAncapFiltersAgency(t *testing.T) {
+	base := serveFixtures(t)
+	sink := &recordingSink{}
+
+	_, err := scrapeAncap(searchRequest{BaseURL: base, Company: "KPMG", Agency: "Defence"}, sink)
+	assert.NoError(t, err)
+	assert.Len(t, sink.matches, 2)
+	assert.True(t, sink.total.Equal(decimal.RequireFromString("200000.50")))
+}
+
+func TestScrapeAncapRetriesFailedPages(t *testing.T) {
+	prevBackoff := pageRetryBackoff
+	pageRetryBackoff = time.Millisecond
+	t.Cleanup(func() { pageRetryBackoff = prevBackoff })
+
+	var mu sync.Mutex
+	page2Calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("Page")
+		if page == "2" {
+			mu.Lock()
+			page2Calls++
+			calls := page2Calls
+			mu.Unlock()
+			if calls <= 2 {
+				http.Error(w, "flaky", http.StatusBadGateway)
+				return
+			}
+		} else {
+			page = "1"
+		}
+		data, _ := os.ReadFile(filepath.Join("testdata", "cn_search_page"+page+".html"))
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+
+	sink := &recordingSink{}
+	res, err := scrapeAncap(searchRequest{BaseURL: srv.URL, Company: "KPMG", PageRetries: 2}, sink)
+	assert.NoError(t, err)
+	assert.True(t, res.Total.Equal(decimal.RequireFromString("742560.50")), "retried page is included")
+	assert.Equal(t, 3, page2Calls, "one initial fetch and two retries")
+	assert.Equal(t, 2, res.Stats.RetriedPages)
+	assert.Empty(t, sink.warnings)
+	assert.Equal(t, []int{1, 2}, sink.progress)
+}
+
+func TestScrapeAncapWarnsAfterRetriesExhausted(t *testing.T) {
+	prevBackoff := pageRetryBackoff
+	pageRetryBackoff = time.Millisecond
+	t.Cleanup(func() { pageRetryBackoff = prevBackoff })
+
+	var mu sync.Mutex
+	page2Calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("Page") == "2" {
+			mu.Lock()
+			page2Calls++
+			mu.Unlock()
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		data, _ := os.ReadFile(filepath.Join("testdata", "cn_search_page1.html"))
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+
+	sink := &recordingSink{}
+	res, err := scrapeAncap(searchRequest{BaseURL: srv.URL, Company: "KPMG", PageRetries: 1}, sink)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page2Calls)
+	assert.Len(t, sink.warnings, 1)
+	assert.Equal(t, res.Warnings, sink.warnings)
+	assert.True(t, res.Total.Equal(decimal.RequireFromString("662560")))
+}
+
+func TestScrapeAncapWarnsWhenFilterMatchesNothing(t *testing.T) {
+	base := serveFixtures(t)
+	sink := &recordingSink{}
+
+	res, err := scrapeAncap(searchRequest{BaseURL: base, Company: "KPMG", Agency: "Treasury"}, sink)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, res.Observed)
+	assert.Equal(t, []string{`AusTender returned 3 contract(s) but none matched agency filter "Treasury"`}, sink.warnings)
+}
+
+func TestScrapeAncapWarnsWhenSourceReturnsNothing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body><p>No results</p></body></html>"))
+	}))
+	t.Cleanup(srv.Close)
+	sink := &recordingSink{}
+
+	res, err := scrapeAncap(searchRequest{BaseURL: srv.URL, Company: "Delloite"}, sink)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, res.Observed)
+	assert.Len(t, sink.warnings, 1)
+	assert.Contains(t, sink.warnings[0], "AusTender returned no contracts across 1 page(s)")
+}
+
+func TestResolveBaseURL(t *testing.T) {
+	base, err := resolveBaseURL("")
+	assert.NoError(t, err)
+	assert.Equal(t, defaultBaseURL, base)
+
+	base, err = resolveBaseURL("http://mirror.example:8080/")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://mirror.example:8080", base, "trailing slash trimmed")
+
+	for _, bad := range []string{"ftp://example.com", "/relative", "example.com", "http://"} {
+		_, err = resolveBaseURL(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestScrapeAncapRejectsBadBaseURL(t *testing.T) {
+	_, err := scrapeAncap(searchRequest{BaseURL: "file:///etc"}, &recordingSink{})
+	assert.Error(t, err)
+}
+
+func TestScrapeAncapAgencyFilterIgnoresCase(t *testing.T) {
+	base := serveFixtures(t)
+
+	res, err := scrapeAncap(searchRequest{BaseURL: base, Company: "kpmg", Agency: "DEFENCE"}, &recordingSink{})
+	assert.NoError(t, err)
+	assert.True(t, res.Total.Equal(decimal.RequireFromString("200000.50")))
+}
+
+func TestScrapeAncapRecordsStats(t *testing.T) {
+	base := serveFixtures(t)
+
+	res, err := scrapeAncap(searchRequest{BaseURL: base, Company: "KPMG"}, &recordingSink{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, res.Stats.Pages)
+	assert.Zero(t, res.Stats.RetriedPages)
+	names := []string{}
+	for _, p := range res.Stats.Phases {
+		names = append(names, p.Name)
+		assert.Positive(t, p.Duration, p.Name)
+	}
+	assert.Equal(t, []string{"fetch", "aggregate"}, names)
+}
+
+func TestScrapeAncapWordMatchesShortCompanyFilters(t *testing.T) {
+	base := serveFixtures(t)
+
+	res, err := scrapeAncap(searchRequest{BaseURL: base, Company: "PMG"}, &recordingSink{})
+	assert.NoError(t, err)
+	assert.Len(t, res.Contracts, 3)
+	assert.Equal(t, map[string]int{companyMatchSubstring: 3}, res.CompanyMatches)
+
+	res, err = scrapeAncap(searchRequest{BaseURL: base, Company: "KPMG", WordMatchUnder: 5}, &recordingSink{})
+	assert.NoError(t, err)
+	assert.True(t, res.Total.Equal(decimal.RequireFromString("742560.50")))
+	assert.Equal(t, map[string]int{companyMatchWord: 3}, res.CompanyMatches)
+
+	// The agency filter is set but the company word match dropped everything
+	sink := &recordingSink{}
+	res, err = scrapeAncap(searchRequest{BaseURL: base, Company: "PMG", Agency: "Defence", WordMatchUnder: 5}, sink)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Contracts)
+	assert.Equal(t, []string{`AusTender returned 3 contract(s) but none matched company filter "PMG" as whole words; see --word-match-under`}, sink.warnings)
 }
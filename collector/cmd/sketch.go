@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"math"
+	"sort"
+)
+
+// sketchAccuracy is the relative error bound of valueSketch quantiles.
+const sketchAccuracy = 0.01
+
+// valueSketch estimates quantiles of positive contract values in bounded
+// memory. Values fall into logarithmic buckets whose width is a fixed
+// fraction of their magnitude (as in DDSketch), so any quantile is within
+// sketchAccuracy of the true value however many values are added; the
+// bucket count grows only with the log of the value range.
+type valueSketch struct {
+	gamma   float64
+	buckets map[int]int
+	zeros   int
+	count   int
+}
+
+func newValueSketch() *valueSketch {
+	return &valueSketch{
+		gamma:   (1 + sketchAccuracy) / (1 - sketchAccuracy),
+		buckets: map[int]int{},
+	}
+}
+
+func (s *valueSketch) add(v float64) {
+	s.count++
+	if v <= 0 {
+		s.zeros++
+		return
+	}
+	s.buckets[int(math.Ceil(math.Log(v)/math.Log(s.gamma)))]++
+}
+
+// quantile returns the estimated value at q in [0, 1], or 0 when empty.
+func (s *valueSketch) quantile(q float64) float64 {
+	if s.count == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(q * float64(s.count)))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank <= s.zeros {
+		return 0
+	}
+	seen := s.zeros
+	keys := make([]int, 0, len(s.buckets))
+	for k := range s.buckets {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, k := range keys {
+		seen += s.buckets[k]
+		if seen >= rank {
+			// Midpoint of (gamma^(k-1), gamma^k] in relative terms
+			return 2 * math.Pow(s.gamma, float64(k)) / (s.gamma + 1)
+		}
+	}
+	return 2 * math.Pow(s.gamma, float64(keys[len(keys)-1])) / (s.gamma + 1)
+}
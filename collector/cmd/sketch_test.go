@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueSketchQuantilesWithinTolerance(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sketch := newValueSketch()
+	values := []float64{}
+	for i := 0; i < 50000; i++ {
+		// Log-normal, like contract values: mostly small, a long tail
+		v := math.Round(math.Exp(11+2*rng.NormFloat64())*100) / 100
+		values = append(values, v)
+		sketch.add(v)
+	}
+	sort.Float64s(values)
+	for _, q := range []float64{0.1, 0.5, 0.9, 0.99} {
+		exact := values[int(math.Ceil(q*float64(len(values))))-1]
+		assert.InEpsilon(t, exact, sketch.quantile(q), sketchAccuracy, "q=%v", q)
+	}
+	assert.Less(t, len(sketch.buckets), 2000, "memory is bounded by the value range, not the count")
+}
+
+func TestValueSketchEdgeCases(t *testing.T) {
+	sketch := newValueSketch()
+	assert.Zero(t, sketch.quantile(0.5))
+	sketch.add(0)
+	sketch.add(100)
+	assert.Zero(t, sketch.quantile(0.5))
+	assert.InEpsilon(t, 100, sketch.quantile(1), sketchAccuracy)
+}
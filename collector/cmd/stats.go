@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// runStats records where a search run spent its time, so a slow run shows
+// whether fetching, retrying or aggregation was the bottleneck.
+type runStats struct {
+	Pages        int           `json:"pages"`
+	RetriedPages int           `json:"retriedPages"`
+	Phases       []phaseTiming `json:"phases"`
+}
+
+// phaseTiming is the wall time of one phase of a run.
+type phaseTiming struct {
+	Name       string        `json:"name"`
+	Duration   time.Duration `json:"-"`
+	DurationMs int64         `json:"durationMs"`
+}
+
+// phase starts timing name; call the returned func when the phase ends.
+func (s *runStats) phase(name string) func() {
+	start := time.Now()
+	return func() {
+		d := time.Since(start)
+		s.Phases = append(s.Phases, phaseTiming{Name: name, Duration: d, DurationMs: d.Milliseconds()})
+	}
+}
+
+// writeTimings prints a one-line timing summary, e.g.
+// "Timings: fetch 1.2s, aggregate 3ms (14 pages, 1 retried)".
+func writeTimings(w io.Writer, s runStats) {
+	parts := []string{}
+	for _, p := range s.Phases {
+		parts = append(parts, fmt.Sprintf("%s %s", p.Name, p.Duration.Round(time.Millisecond)))
+	}
+	fmt.Fprintf(w, "Timings: %s (%d pages, %d retried)\n", strings.Join(parts, ", "), s.Pages, s.RetriedPages)
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTimings(t *testing.T) {
+	var buf bytes.Buffer
+	writeTimings(&buf, runStats{
+		Pages:        14,
+		RetriedPages: 1,
+		Phases: []phaseTiming{
+			{Name: "fetch", Duration: 1234 * time.Millisecond},
+			{Name: "aggregate", Duration: 3 * time.Millisecond},
+		},
+	})
+	assert.Equal(t, "Timings: fetch 1.234s, aggregate 3ms (14 pages, 1 retried)\n", buf.String())
+}
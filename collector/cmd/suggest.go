@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+)
+
+// editDistance is the Levenshtein distance between a and b in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// filterDistance is how far filter is from matching name the way the
+// contains-style filters do: the smallest edit distance between filter and
+// any run of consecutive words in name with the same word count.
+func filterDistance(filter, name string) int {
+	f, words := normalizeName(filter), strings.Fields(normalizeName(name))
+	n := len(strings.Fields(f))
+	best := editDistance(f, strings.Join(words, " "))
+	for i := 0; i+n <= len(words); i++ {
+		best = min(best, editDistance(f, strings.Join(words[i:i+n], " ")))
+	}
+	return best
+}
+
+// suggestName returns the candidate that filter most plausibly misspells,
+// allowing roughly one edit per four characters, or "" if none is close.
+func suggestName(filter string, candidates []string) string {
+	limit := max(1, len([]rune(normalizeName(filter)))/4)
+	sorted := append([]string{}, candidates...)
+	sort.Strings(sorted)
+	best, bestDist := "", limit+1
+	for _, c := range sorted {
+		if d := filterDistance(filter, c); d > 0 && d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
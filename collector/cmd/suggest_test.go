@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("kpmg", "kpmg"))
+	assert.Equal(t, 2, editDistance("delloite", "deloitte"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+	assert.Equal(t, 1, editDistance("université", "universite"))
+}
+
+func TestSuggestName(t *testing.T) {
+	agencies := []string{"Department of Defence", "Australian Taxation Office", "Services Australia", "Department of Health and Aged Care"}
+	for filter, want := range map[string]string{
+		"Defense":               "Department of Defence",
+		"Australian Taxaton":    "Australian Taxation Office",
+		"Servics Australia":     "Services Australia",
+		"department of helth":   "Department of Health and Aged Care",
+		"Treasury":              "",
+		"Department of Defence": "",
+		"Xyz":                   "",
+	} {
+		assert.Equal(t, want, suggestName(filter, agencies), filter)
+	}
+	assert.Equal(t, "Deloitte", suggestName("Delloite", []string{"Deloitte", "KPMG", "Accenture"}))
+}
+
+func TestScrapeAncapSuggestsAgency(t *testing.T) {
+	base := serveFixtures(t)
+	sink := &recordingSink{}
+
+	_, err := scrapeAncap(searchRequest{BaseURL: base, Company: "KPMG", Agency: "Defense"}, sink)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`AusTender returned 3 contract(s) but none matched agency filter "Defense"; did you mean "Department of Defence"?`}, sink.warnings)
+
+	_, err = scrapeAncap(searchRequest{BaseURL: base, Portfolio: "Defense"}, sink)
+	assert.EqualError(t, err, `unknown portfolio "Defense"; did you mean "Defence"?`)
+}
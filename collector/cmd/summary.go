@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// runSummary is the machine-readable outcome of a CLI run, written when
+// --summary-file is set so CI pipelines can inspect what happened.
+type runSummary struct {
+	Request    searchRequest   `json:"request"`
+	Total      decimal.Decimal `json:"total"`
+	Totals     searchTotals    `json:"totals"`
+	MatchCount int             `json:"matchCount"`
+	// Largest suppliers by total, and the publish dates the matches span
+	TopSuppliers []supplierTotal `json:"topSuppliers"`
+	DateRange    *publishRange   `json:"dateRange,omitempty"`
+	// Notices matched per company match mode: word or substring
+	CompanyMatches map[string]int `json:"companyMatches,omitempty"`
+	Observed       int            `json:"observed"`
+	Warnings       []string       `json:"warnings"`
+	Stats          runStats       `json:"stats"`
+	StartedAt      time.Time      `json:"startedAt"`
+	DurationMs     int64          `json:"durationMs"`
+	ExitStatus     string         `json:"exitStatus"`
+	Error          string         `json:"error,omitempty"`
+}
+
+func newRunSummary(req searchRequest, started time.Time, res searchResult, runErr error) runSummary {
+	s := runSummary{
+		Request:        req,
+		Total:          res.Total,
+		Totals:         res.Totals,
+		MatchCount:     len(res.Contracts),
+		TopSuppliers:   res.TopSuppliers,
+		DateRange:      res.DateRange,
+		CompanyMatches: res.CompanyMatches,
+		Observed:       res.Observed,
+		Warnings:       res.Warnings,
+		Stats:          res.Stats,
+		StartedAt:      started,
+		DurationMs:     time.Since(started).Milliseconds(),
+		ExitStatus:     "ok",
+	}
+	if s.Warnings == nil {
+		s.Warnings = []string{}
+	}
+	if s.TopSuppliers == nil {
+		s.TopSuppliers = []supplierTotal{}
+	}
+	if s.Stats.Phases == nil {
+		s.Stats.Phases = []phaseTiming{}
+	}
+	if runErr != nil {
+		s.ExitStatus = "error"
+		s.Error = runErr.Error()
+	}
+	return s
+}
+
+// redactedRunSummary is newRunSummary for an unredacted result, with
+// supplier names redacted when --redact-suppliers is on.
+func redactedRunSummary(req searchRequest, started time.Time, res searchResult, runErr error) runSummary {
+	req.Company = redactor.name(req.Company)
+	return newRunSummary(req, started, redactor.result(res), runErr)
+}
+
+func writeRunSummary(path string, s runSummary) error {
+	return writeSummaryJSON(path, s)
+}
+
+// writeRunSummaries writes the summaries of a command that ran several
+// searches, such as compare, as a JSON array.
+func writeRunSummaries(path string, s []runSummary) error {
+	return writeSummaryJSON(path, s)
+}
+
+func writeSummaryJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
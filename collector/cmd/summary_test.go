@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRunSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	req := searchRequest{Company: "KPMG", Agency: "Australian National Audit Office"}
+	res := searchResult{
+		Contracts: []*contract{{CN_ID: "CN1"}, {CN_ID: "CN2"}},
+		Total:     decimal.RequireFromString("1234.50"),
+	}
+
+	err := writeRunSummary(path, newRunSummary(req, time.Now(), res, nil))
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var got runSummary
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, req, got.Request)
+	assert.True(t, got.Total.Equal(res.Total), "total round-trips as a decimal")
+	assert.Equal(t, 2, got.MatchCount)
+	assert.Equal(t, []string{}, got.Warnings)
+	assert.Equal(t, "ok", got.ExitStatus)
+}
+
+func TestRunSummaryRecordsError(t *testing.T) {
+	s := newRunSummary(searchRequest{}, time.Now(), searchResult{}, errors.New("boom"))
+	assert.Equal(t, "error", s.ExitStatus)
+	assert.Equal(t, "boom", s.Error)
+}
+
+func TestSubcommandsWriteRunSummary(t *testing.T) {
+	base := serveFixtures(t)
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "breakdown.json")
+	_, _, err := runRoot(t, "breakdown", "--base-url", base, "--c", "KPMG", "--summary-file", path)
+	assert.NoError(t, err)
+	var s runSummary
+	data, _ := os.ReadFile(path)
+	assert.NoError(t, json.Unmarshal(data, &s))
+	assert.Equal(t, 3, s.MatchCount)
+	assert.Equal(t, "ok", s.ExitStatus)
+
+	path = filepath.Join(dir, "compare.json")
+	_, _, err = runRoot(t, "compare", "agencies", "--base-url", base, "--c", "KPMG", "--left", "Audit", "--right", "Defence", "--summary-file", path)
+	assert.NoError(t, err)
+	var sides []runSummary
+	data, _ = os.ReadFile(path)
+	assert.NoError(t, json.Unmarshal(data, &sides))
+	assert.Len(t, sides, 2)
+	assert.Equal(t, "Audit", sides[0].Request.Agency)
+	assert.Equal(t, "Defence", sides[1].Request.Agency)
+	assert.Equal(t, 1, sides[0].MatchCount)
+	assert.Equal(t, 2, sides[1].MatchCount)
+}
+
+func TestOutputIsRootOnly(t *testing.T) {
+	_, _, err := runRoot(t, "breakdown", "--output", "csv")
+	assert.Error(t, err, "subcommands render their own output")
+}
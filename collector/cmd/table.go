@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ANSI styles for table cells.
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiGreen = "\x1b[32m"
+	ansiRed   = "\x1b[31m"
+)
+
+// tableColumn is a heading and its alignment; currency columns align right.
+type tableColumn struct {
+	Heading string
+	Right   bool
+}
+
+// tableCell is one value and an optional ANSI style used when colour is on.
+type tableCell struct {
+	Text  string
+	Style string
+}
+
+func plainCells(texts ...string) []tableCell {
+	cells := make([]tableCell, len(texts))
+	for i, t := range texts {
+		cells[i] = tableCell{Text: t}
+	}
+	return cells
+}
+
+// textTable renders aligned terminal tables. Styles are applied after
+// padding so escape codes never affect column widths.
+type textTable struct {
+	columns []tableColumn
+	rows    [][]tableCell
+}
+
+func newTextTable(columns ...tableColumn) *textTable {
+	return &textTable{columns: columns}
+}
+
+func (t *textTable) addRow(cells ...tableCell) {
+	t.rows = append(t.rows, cells)
+}
+
+// colourEnabled reports whether styled output suits w: a terminal, with
+// NO_COLOR (https://no-color.org) unset in cfg.
+func colourEnabled(w io.Writer, cfg config) bool {
+	return !cfg.NoColor && isTerminal(w)
+}
+
+// render writes the table to w, styled when colour is true.
+func (t *textTable) render(w io.Writer, colour bool) error {
+	widths := make([]int, len(t.columns))
+	for i, c := range t.columns {
+		widths[i] = utf8.RuneCountInString(c.Heading)
+	}
+	for _, row := range t.rows {
+		for i, c := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(c.Text))
+		}
+	}
+
+	var b strings.Builder
+	line := func(cells []tableCell) {
+		var l strings.Builder
+		for i, c := range cells {
+			if i > 0 {
+				l.WriteString("  ")
+			}
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(c.Text))
+			text := c.Text
+			if colour && c.Style != "" {
+				text = c.Style + text + ansiReset
+			}
+			if t.columns[i].Right {
+				l.WriteString(pad + text)
+			} else {
+				l.WriteString(text + pad)
+			}
+		}
+		b.WriteString(strings.TrimRight(l.String(), " "))
+		b.WriteByte('\n')
+	}
+
+	headings := make([]tableCell, len(t.columns))
+	for i, c := range t.columns {
+		headings[i] = tableCell{Text: c.Heading, Style: ansiBold}
+	}
+	line(headings)
+	for _, row := range t.rows {
+		line(row)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextTableAlignsAndColours(t *testing.T) {
+	table := newTextTable(tableColumn{Heading: "Key"}, tableColumn{Heading: "Total", Right: true})
+	table.addRow(tableCell{Text: "Université"}, tableCell{Text: "$1.00", Style: ansiGreen})
+	table.addRow(plainCells("b", "$1,000.00")...)
+
+	var plain bytes.Buffer
+	assert.NoError(t, table.render(&plain, false))
+	assert.Equal(t, "Key             Total\nUniversité      $1.00\nb           $1,000.00\n", plain.String())
+
+	var coloured bytes.Buffer
+	assert.NoError(t, table.render(&coloured, true))
+	assert.Contains(t, coloured.String(), "     "+ansiGreen+"$1.00"+ansiReset+"\n", "styles wrap padded text without affecting width")
+}
+
+func TestColourDisabledForNonTerminals(t *testing.T) {
+	assert.False(t, colourEnabled(&bytes.Buffer{}, config{}))
+}
+
+func TestBreakdownTableGolden(t *testing.T) {
+	contracts := []*contract{
+		{Supplier_Name: "KPMG", Contract_Value: decimal.RequireFromString("542560")},
+		{Supplier_Name: "KPMG", Contract_Value: decimal.RequireFromString("120000")},
+		{Supplier_Name: "Deloitte", Contract_Value: decimal.RequireFromString("80000.50")},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, writeBreakdown(&buf, "supplier", groupContractsWithStats(contracts, supplierKey), false))
+	assertGolden(t, "breakdown_supplier.golden", buf.Bytes())
+}
+
+func TestComparisonTableGolden(t *testing.T) {
+	rows := []comparisonRow{
+		{Label: "2017-18", Left: decimal.NewFromInt(150), Right: decimal.NewFromInt(250)},
+		{Label: "2018-19", Left: decimal.NewFromInt(50)},
+		{Label: "Total", Left: decimal.NewFromInt(200), Right: decimal.NewFromInt(250)},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, writeComparison(&buf, "ATO", "Home Affairs", rows, false))
+	assertGolden(t, "compare_year.golden", buf.Bytes())
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// groupedSupplierKey returns a supplier key that folds name variants into
+// the shortest name whose words they contain, so "KPMG Peat Marwick - ACT"
+// and "KPMG Australia" both group under "KPMG" when "KPMG" itself is among
+// the suppliers. Names differing only in case or spacing always share a
+// group, labelled with the alphabetically first spelling.
+func groupedSupplierKey(contracts []*contract) func(*contract) string {
+	labels := map[string]string{}
+	for _, c := range contracts {
+		n := normalizeName(c.Supplier_Name)
+		if n == "" {
+			continue
+		}
+		if l, ok := labels[n]; !ok || c.Supplier_Name < l {
+			labels[n] = c.Supplier_Name
+		}
+	}
+	names := make([]string, 0, len(labels))
+	for n := range labels {
+		names = append(names, n)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+
+	roots := []string{}
+	group := map[string]string{}
+	for _, n := range names {
+		group[n] = n
+		for _, r := range roots {
+			if containsWords(n, r) {
+				group[n] = r
+				break
+			}
+		}
+		if group[n] == n {
+			roots = append(roots, n)
+		}
+	}
+	return func(c *contract) string {
+		n := normalizeName(c.Supplier_Name)
+		if n == "" {
+			return unknownKey
+		}
+		return labels[group[n]]
+	}
+}
+
+// publishedSince keeps the contracts published on or after since. Undated
+// contracts are dropped, as they can't be placed in the window.
+func publishedSince(contracts []*contract, since time.Time) []*contract {
+	kept := []*contract{}
+	for _, c := range contracts {
+		if t, err := parsePublishDate(c.Publish_Date); err == nil && !t.Before(since) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// writeTopSuppliers prints the ranking as a table.
+func writeTopSuppliers(w io.Writer, top []supplierTotal, colour bool) error {
+	t := newTextTable(
+		tableColumn{Heading: "Rank", Right: true},
+		tableColumn{Heading: "Supplier"},
+		tableColumn{Heading: "Total", Right: true},
+		tableColumn{Heading: "Contracts", Right: true},
+	)
+	for i, s := range top {
+		t.addRow(plainCells(strconv.Itoa(i+1), s.Name, formatMoney(s.Total), strconv.Itoa(s.Count))...)
+	}
+	return t.render(w, colour)
+}
+
+var topSuppliersCmd = &cobra.Command{
+	Use:   "top-suppliers",
+	Short: "Rank suppliers by total contract value",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, _ := cmd.Flags().GetInt("n")
+		years, _ := cmd.Flags().GetInt("lookback-years")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if n < 1 {
+			return fmt.Errorf("-n must be at least 1")
+		}
+		if years < 0 {
+			return fmt.Errorf("--lookback-years must not be negative")
+		}
+
+		// Group on real names; pseudonyms would hide which names are variants
+		cfg := resolveConfig(os.Environ())
+		req := requestFromFlags(cmd, cfg)
+		res, err := unredactedSearch(cmd, req)
+		if err != nil {
+			return err
+		}
+		contracts := res.Contracts
+		if years > 0 {
+			contracts = publishedSince(contracts, time.Now().AddDate(-years, 0, 0))
+		}
+		top := rankSuppliers(contracts, groupedSupplierKey(contracts), n)
+		for i := range top {
+			top[i].Name = redactor.name(top[i].Name)
+		}
+		if asJSON {
+			writeGSTNote(cmd.ErrOrStderr(), req.NormaliseGST)
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(top)
+		}
+		if err := writeTopSuppliers(cmd.OutOrStdout(), top, colourEnabled(cmd.OutOrStdout(), cfg)); err != nil {
+			return err
+		}
+		writeGSTNote(cmd.OutOrStdout(), req.NormaliseGST)
+		return nil
+	},
+}
+
+func init() {
+	topSuppliersCmd.Flags().IntP("n", "n", 20, "Number of suppliers to list")
+	topSuppliersCmd.Flags().Int("lookback-years", 0, "Only count contracts published in the last this many years (default all)")
+	topSuppliersCmd.Flags().Bool("json", false, "Print results as JSON")
+	rootCmd.AddCommand(topSuppliersCmd)
+}
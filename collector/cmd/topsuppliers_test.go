@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupedSupplierKey(t *testing.T) {
+	contracts := []*contract{
+		{Supplier_Name: "KPMG Peat Marwick - ACT"},
+		{Supplier_Name: "kpmg"},
+		{Supplier_Name: "KPMG"},
+		{Supplier_Name: "KPMG Australia"},
+		{Supplier_Name: "EY"},
+		{Supplier_Name: "Sydney Water"},
+		{Supplier_Name: "Deloitte  Touche"},
+		{Supplier_Name: "Deloitte Touche Tohmatsu"},
+		{Supplier_Name: ""},
+	}
+	key := groupedSupplierKey(contracts)
+	got := []string{}
+	for _, c := range contracts {
+		got = append(got, key(c))
+	}
+	assert.Equal(t, []string{
+		"KPMG", "KPMG", "KPMG", "KPMG",
+		"EY", "Sydney Water",
+		"Deloitte  Touche", "Deloitte  Touche",
+		unknownKey,
+	}, got)
+
+	// Without a bare "KPMG" the variants stay apart
+	apart := []*contract{{Supplier_Name: "KPMG Australia"}, {Supplier_Name: "KPMG Peat Marwick"}}
+	key = groupedSupplierKey(apart)
+	assert.NotEqual(t, key(apart[0]), key(apart[1]))
+}
+
+func TestPublishedSince(t *testing.T) {
+	contracts := []*contract{
+		{CN_ID: "old", Publish_Date: "30-Jun-2019"},
+		{CN_ID: "edge", Publish_Date: "1-Jul-2019"},
+		{CN_ID: "new", Publish_Date: "15-Mar-2021"},
+		{CN_ID: "undated"},
+	}
+	kept := publishedSince(contracts, time.Date(2019, time.July, 1, 0, 0, 0, 0, time.UTC))
+	ids := []string{}
+	for _, c := range kept {
+		ids = append(ids, c.CN_ID)
+	}
+	assert.Equal(t, []string{"edge", "new"}, ids)
+}
+
+func TestTopSuppliersCommand(t *testing.T) {
+	base := serveFixtures(t)
+	out, _, err := runRoot(t, "top-suppliers", "--base-url", base, "--c", "KPMG", "-n", "1", "--json", "--no-progress")
+	assert.NoError(t, err)
+	var top []supplierTotal
+	assert.NoError(t, json.Unmarshal([]byte(out), &top))
+	assert.Len(t, top, 1)
+	assert.Equal(t, "KPMG", top[0].Name)
+	assert.Equal(t, 3, top[0].Count)
+	assert.True(t, top[0].Total.Equal(decimal.RequireFromString("742560.50")))
+
+	out, _, err = runRoot(t, "top-suppliers", "--base-url", base, "--c", "KPMG", "--no-progress")
+	assert.NoError(t, err)
+	assert.Equal(t, "Rank  Supplier        Total  Contracts\n   1  KPMG      $742,560.50          3\n", out)
+
+	_, _, err = runRoot(t, "top-suppliers", "--base-url", base, "-n", "0")
+	assert.Error(t, err)
+}
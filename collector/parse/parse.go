@@ -0,0 +1,78 @@
+// Package parse turns scraped money and date text into typed values. It is
+// shared by every source so malformed text is rejected the same way
+// everywhere rather than each scraper growing its own lenient parser.
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AusTenderDateLayouts are the date formats AusTender publishes, e.g.
+// "6-Feb-2018". Sources pass their own layout lists to ParseDate.
+var AusTenderDateLayouts = []string{"2-Jan-2006"}
+
+// MaxMoney bounds parsed amounts. Larger values are almost certainly two
+// numbers run together by a scrape rather than a real contract.
+var MaxMoney = decimal.New(1, 12)
+
+// ErrZeroDate is returned for dates that parse to the zero time, which
+// would otherwise yield nonsense such as FY "0000-01".
+var ErrZeroDate = errors.New("zero date")
+
+var moneyPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// ParseMoney parses amounts such as "$542,560.00", "AUD 1,000" or
+// "($250.00)". It rejects anything that is not a single plain number once
+// currency markers, thousands separators and spaces are removed.
+func ParseMoney(s string) (decimal.Decimal, error) {
+	num := strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(num, "(") && strings.HasSuffix(num, ")") {
+		negative = true
+		num = strings.TrimSpace(num[1 : len(num)-1])
+	}
+	if strings.HasPrefix(num, "-") {
+		negative = !negative
+		num = strings.TrimSpace(num[1:])
+	}
+	num = strings.TrimPrefix(num, "AUD")
+	num = strings.TrimSpace(num)
+	num = strings.TrimPrefix(num, "$")
+	num = strings.NewReplacer(",", "", " ", "").Replace(num)
+	if !moneyPattern.MatchString(num) {
+		return decimal.Zero, fmt.Errorf("unrecognised amount %q", s)
+	}
+	v, err := decimal.NewFromString(num)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("unrecognised amount %q: %w", s, err)
+	}
+	if v.GreaterThan(MaxMoney) {
+		return decimal.Zero, fmt.Errorf("amount %q exceeds %s", s, MaxMoney.String())
+	}
+	if negative {
+		v = v.Neg()
+	}
+	return v, nil
+}
+
+// ParseDate parses s with the first of layouts that fits.
+func ParseDate(s string, layouts ...string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		if t.IsZero() {
+			return t, ErrZeroDate
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", s)
+}
@@ -0,0 +1,81 @@
+package parse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	cases := map[string]string{
+		"$542,560.00":   "542560",
+		" $80,000.50 ":  "80000.5",
+		"AUD 1,000":     "1000",
+		"1000":          "1000",
+		"-$250.00":      "-250",
+		"($250.00)":     "-250",
+		"$1 234 567.89": "1234567.89",
+	}
+	for in, want := range cases {
+		got, err := ParseMoney(in)
+		assert.NoError(t, err, in)
+		assert.True(t, got.Equal(decimal.RequireFromString(want)), "%s parsed as %s", in, got)
+	}
+}
+
+func TestParseMoneyRejectsMalformed(t *testing.T) {
+	for _, in := range []string{"", "BlahBlah", "$", "1.2.3", "$5 (incl GST)", "--5", "$1,000.00$2,000.00", "99999999999999"} {
+		v, err := ParseMoney(in)
+		assert.Error(t, err, in)
+		assert.True(t, v.IsZero(), in)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	got, err := ParseDate(" 6-Feb-2018 ", AusTenderDateLayouts...)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2018, time.February, 6, 0, 0, 0, 0, time.UTC), got)
+
+	got, err = ParseDate("2018-02-06", append(AusTenderDateLayouts, "2006-01-02")...)
+	assert.NoError(t, err)
+	assert.Equal(t, 2018, got.Year())
+
+	for _, in := range []string{"", "31-Feb-2018", "2018-02-06"} {
+		_, err := ParseDate(in, AusTenderDateLayouts...)
+		assert.Error(t, err, in)
+	}
+	_, err = ParseDate("1-Jan-0001", AusTenderDateLayouts...)
+	assert.ErrorIs(t, err, ErrZeroDate)
+}
+
+func FuzzParseMoney(f *testing.F) {
+	for _, seed := range []string{"$542,560.00", "($1.00)", "AUD 5", "1e9", "", "-"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		v, err := ParseMoney(s)
+		if err != nil {
+			if !v.IsZero() {
+				t.Fatalf("%q: error %v with non-zero value %s", s, err, v)
+			}
+			return
+		}
+		if v.Abs().GreaterThan(MaxMoney) {
+			t.Fatalf("%q parsed to out-of-range %s", s, v)
+		}
+	})
+}
+
+func FuzzParseDate(f *testing.F) {
+	for _, seed := range []string{"6-Feb-2018", "31-Feb-2018", "1-Jan-0001", ""} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		got, err := ParseDate(s, AusTenderDateLayouts...)
+		if err == nil && got.IsZero() {
+			t.Fatalf("%q parsed to the zero time without error", s)
+		}
+	})
+}